@@ -14,6 +14,7 @@ type BizData struct {
 	UserID string         `json:"user_id"`
 	FromIP string         `json:"from_ip"`
 	LogID  string         `json:"log_id"`
+	Locale string         `json:"locale"`
 	Custom map[string]any `json:"custom"`
 }
 
@@ -39,6 +40,7 @@ func (b *BizData) DeepCopy() *BizData {
 	newData := NewBizData()
 	newData.UserID = b.UserID
 	newData.LogID = b.LogID
+	newData.Locale = b.Locale
 	for k, v := range b.Custom {
 		newData.Custom[k] = v
 	}
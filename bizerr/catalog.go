@@ -0,0 +1,186 @@
+package bizerr
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed locales
+var defaultLocales embed.FS
+
+// MessageCatalog 按错误码与语言维护对外展示的错误消息模板，模板语法为 text/template，参数来自 BusinessError.Params
+type MessageCatalog struct {
+	lock          sync.RWMutex
+	defaultLocale string
+	messages      map[string]map[ErrorCode]*template.Template
+}
+
+var (
+	globalCatalogLock sync.RWMutex
+	globalCatalog     *MessageCatalog
+)
+
+func init() {
+	catalog := NewMessageCatalog("en")
+	if err := catalog.LoadFS(defaultLocales, "locales"); err != nil {
+		panic(err)
+	}
+	globalCatalog = catalog
+}
+
+// SetMessageCatalog 设置全局消息目录
+func SetMessageCatalog(catalog *MessageCatalog) {
+	globalCatalogLock.Lock()
+	defer globalCatalogLock.Unlock()
+	globalCatalog = catalog
+}
+
+// GetMessageCatalog 获取全局消息目录，默认包含内置错误码的 en、zh 消息
+func GetMessageCatalog() *MessageCatalog {
+	globalCatalogLock.RLock()
+	defer globalCatalogLock.RUnlock()
+	return globalCatalog
+}
+
+// NewMessageCatalog 创建消息目录，defaultLocale 用于请求语言无匹配时的兜底
+func NewMessageCatalog(defaultLocale string) *MessageCatalog {
+	return &MessageCatalog{
+		defaultLocale: normalizeLocale(defaultLocale),
+		messages:      make(map[string]map[ErrorCode]*template.Template),
+	}
+}
+
+// Add 添加或覆盖某个语言下错误码的消息模板
+func (c *MessageCatalog) Add(locale string, code ErrorCode, message string) error {
+	locale = normalizeLocale(locale)
+	tmpl, err := template.New(locale + "/" + string(code)).Parse(message)
+	if err != nil {
+		return Wrap(err, InvalidInput, "parse message template failed")
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.messages[locale] == nil {
+		c.messages[locale] = make(map[ErrorCode]*template.Template)
+	}
+	c.messages[locale][code] = tmpl
+	return nil
+}
+
+// LoadFS 加载目录下的 yaml/yml/json 文件，文件名即语言，如 zh-CN.yaml，内容为错误码到消息模板的映射
+func (c *MessageCatalog) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		ext := path.Ext(name)
+		data, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return err
+		}
+
+		messages := map[ErrorCode]string{}
+		switch ext {
+		case ".yaml", ".yml":
+			err = yaml.Unmarshal(data, &messages)
+		case ".json":
+			err = json.Unmarshal(data, &messages)
+		default:
+			continue
+		}
+		if err != nil {
+			return Wrap(err, InvalidInput, "load message catalog failed: "+name)
+		}
+
+		locale := strings.TrimSuffix(name, ext)
+		for code, message := range messages {
+			if err := c.Add(locale, code, message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Match 返回目录中与 locale 最匹配的语言，依次尝试完整语言（zh-cn）与主语言（zh），无匹配时 ok 为 false
+func (c *MessageCatalog) Match(locale string) (string, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.match(normalizeLocale(locale))
+}
+
+// Localize 渲染错误码在指定语言下的消息，无匹配语言时使用默认语言，仍无对应消息时 ok 为 false
+func (c *MessageCatalog) Localize(code ErrorCode, locale string, params map[string]any) (string, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	tmpl := c.lookup(code, normalizeLocale(locale))
+	if tmpl == nil {
+		tmpl = c.lookup(code, c.defaultLocale)
+	}
+	if tmpl == nil {
+		return "", false
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := tmpl.Execute(buffer, params); err != nil {
+		return "", false
+	}
+	return buffer.String(), true
+}
+
+func (c *MessageCatalog) lookup(code ErrorCode, locale string) *template.Template {
+	matched, ok := c.match(locale)
+	if !ok {
+		return nil
+	}
+	return c.messages[matched][code]
+}
+
+func (c *MessageCatalog) match(locale string) (string, bool) {
+	if locale == "" {
+		return "", false
+	}
+	if _, ok := c.messages[locale]; ok {
+		return locale, true
+	}
+	if idx := strings.Index(locale, "-"); idx > 0 {
+		if _, ok := c.messages[locale[:idx]]; ok {
+			return locale[:idx], true
+		}
+	}
+	return "", false
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// LocalizeMessage 使用全局消息目录渲染错误对外展示的消息。错误带有自定义消息时保持原样，
+// 除非设置了模板参数或错误码在 CodeMeta.AlwaysLocalize 中声明始终本地化；无对应消息时返回原始消息
+func LocalizeMessage(be BusinessError, locale string) string {
+	if locale == "" {
+		return be.Message()
+	}
+	if be.Message() != "" && len(be.Params()) == 0 && !GetCodeMeta(be.Code()).AlwaysLocalize {
+		return be.Message()
+	}
+	if message, ok := GetMessageCatalog().Localize(be.Code(), locale, be.Params()); ok {
+		return message
+	}
+	return be.Message()
+}
@@ -0,0 +1,112 @@
+package bizerr
+
+import "time"
+
+// ErrorDetails 定义可随错误序列化的结构化详情，供调用方按字段读取而非解析 message
+type ErrorDetails struct {
+	// FieldViolations 参数校验失败的字段列表，常用于 InvalidInput
+	FieldViolations []FieldViolation `json:"field_violations,omitempty"`
+	// RetryInfo 重试建议，常用于 RateLimited
+	RetryInfo *RetryInfo `json:"retry_info,omitempty"`
+	// ResourceInfo 缺失或冲突的资源信息，常用于 NotFound
+	ResourceInfo *ResourceInfo `json:"resource_info,omitempty"`
+	// Extra 其他自定义详情
+	Extra map[string]any `json:"extra,omitempty"`
+}
+
+// FieldViolation 描述单个字段的校验失败
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// RetryInfo 描述客户端应等待多久后重试
+type RetryInfo struct {
+	RetryAfterMillSec int64 `json:"retry_after_ms"`
+}
+
+// ResourceInfo 描述相关资源的类型与 ID
+type ResourceInfo struct {
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+}
+
+// RetryAfter 返回建议的重试等待时长
+func (r *RetryInfo) RetryAfter() time.Duration {
+	if r == nil {
+		return 0
+	}
+	return time.Duration(r.RetryAfterMillSec) * time.Millisecond
+}
+
+func (d *ErrorDetails) clone() *ErrorDetails {
+	if d == nil {
+		return nil
+	}
+	newDetails := &ErrorDetails{}
+	if d.FieldViolations != nil {
+		newDetails.FieldViolations = append([]FieldViolation{}, d.FieldViolations...)
+	}
+	if d.RetryInfo != nil {
+		retryInfo := *d.RetryInfo
+		newDetails.RetryInfo = &retryInfo
+	}
+	if d.ResourceInfo != nil {
+		resourceInfo := *d.ResourceInfo
+		newDetails.ResourceInfo = &resourceInfo
+	}
+	if d.Extra != nil {
+		newDetails.Extra = make(map[string]any, len(d.Extra))
+		for k, v := range d.Extra {
+			newDetails.Extra[k] = v
+		}
+	}
+	return newDetails
+}
+
+// Details 返回错误详情，未设置时返回 nil
+func (e *businessError) Details() *ErrorDetails {
+	return e.details
+}
+
+// WithFieldViolation 创建一个新的错误并追加字段校验失败信息
+func (e *businessError) WithFieldViolation(field, description string) BusinessError {
+	newErr := e.cloneWithDetails()
+	newErr.details.FieldViolations = append(newErr.details.FieldViolations, FieldViolation{
+		Field:       field,
+		Description: description,
+	})
+	return newErr
+}
+
+// WithRetryAfter 创建一个新的错误并设置重试等待时长
+func (e *businessError) WithRetryAfter(retryAfter time.Duration) BusinessError {
+	newErr := e.cloneWithDetails()
+	newErr.details.RetryInfo = &RetryInfo{RetryAfterMillSec: retryAfter.Milliseconds()}
+	return newErr
+}
+
+// WithResourceInfo 创建一个新的错误并设置相关资源信息
+func (e *businessError) WithResourceInfo(resourceType, resourceID string) BusinessError {
+	newErr := e.cloneWithDetails()
+	newErr.details.ResourceInfo = &ResourceInfo{ResourceType: resourceType, ResourceID: resourceID}
+	return newErr
+}
+
+// WithExtra 创建一个新的错误并设置自定义详情
+func (e *businessError) WithExtra(key string, value any) BusinessError {
+	newErr := e.cloneWithDetails()
+	if newErr.details.Extra == nil {
+		newErr.details.Extra = make(map[string]any)
+	}
+	newErr.details.Extra[key] = value
+	return newErr
+}
+
+func (e *businessError) cloneWithDetails() *businessError {
+	newErr := e.clone()
+	if newErr.details == nil {
+		newErr.details = &ErrorDetails{}
+	}
+	return newErr
+}
@@ -0,0 +1,102 @@
+package bizerr
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ragpanda/go-toolkit/utils"
+)
+
+// DumpFormat 错误码目录的导出格式
+type DumpFormat string
+
+const (
+	DumpFormatMarkdown DumpFormat = "markdown"
+	DumpFormatJSON     DumpFormat = "json"
+)
+
+// CatalogEntry 错误码目录中的一项
+type CatalogEntry struct {
+	Code        ErrorCode `json:"code"`
+	NumericID   int       `json:"numeric_id"`
+	Namespace   string    `json:"namespace"`
+	HTTPStatus  int       `json:"http_status"`
+	RetryClass  string    `json:"retry_class"`
+	Description string    `json:"description"`
+}
+
+// DumpRegistry 将所有已注册的错误码以 Markdown 或 JSON 输出，供前端等调用方查阅
+func DumpRegistry(w io.Writer, format DumpFormat) error {
+	entries := make([]*CatalogEntry, 0)
+	for _, entry := range ListCodes() {
+		entries = append(entries, &CatalogEntry{
+			Code:        entry.Code,
+			NumericID:   entry.NumericID,
+			Namespace:   entry.Namespace,
+			HTTPStatus:  entry.HTTPStatus,
+			RetryClass:  entry.RetryClass.String(),
+			Description: entry.Description,
+		})
+	}
+
+	switch format {
+	case DumpFormatJSON:
+		data, err := utils.Marshal(entries)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case DumpFormatMarkdown, "":
+		builder := &strings.Builder{}
+		builder.WriteString("| Code | Numeric ID | Namespace | HTTP Status | Retry | Description |\n")
+		builder.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+		for _, e := range entries {
+			fmt.Fprintf(builder, "| %s | %d | %s | %d | %s | %s |\n",
+				e.Code, e.NumericID, e.Namespace, e.HTTPStatus, e.RetryClass,
+				strings.ReplaceAll(e.Description, "|", "\\|"))
+		}
+		_, err := io.WriteString(w, builder.String())
+		return err
+	default:
+		return ErrInvalidInput.WithMessage(fmt.Sprintf("unsupported dump format %q", format))
+	}
+}
+
+// RunDumpCommand 解析命令行参数并导出本进程内已注册的错误码，供服务在自己的 main 或 go:generate 中调用，
+// 需先 import 注册错误码的包。支持参数 -format markdown|json 与 -o 输出文件（默认输出到 stdout）
+//
+//	//go:generate go run ./cmd/errcode-dump -format markdown -o ERRORS.md
+//	func main() {
+//		if err := bizerr.RunDumpCommand(os.Args[1:], os.Stdout); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+func RunDumpCommand(args []string, stdout io.Writer) error {
+	flagSet := flag.NewFlagSet("errcode-dump", flag.ContinueOnError)
+	flagSet.SetOutput(stdout)
+	format := flagSet.String("format", string(DumpFormatMarkdown), "output format, markdown or json")
+	output := flagSet.String("o", "", "output file, stdout if empty")
+	if err := flagSet.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	if *output == "" {
+		return DumpRegistry(stdout, DumpFormat(*format))
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := DumpRegistry(file, DumpFormat(*format)); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -2,11 +2,14 @@ package bizerr
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime"
 	"strings"
+	"time"
 
 	biz2 "github.com/ragpanda/go-toolkit/biz"
+	"github.com/ragpanda/go-toolkit/utils"
 )
 
 // BusinessError 定义业务错误接口
@@ -18,6 +21,19 @@ type BusinessError interface {
 	WithMessage(message string) BusinessError
 	WithStack(ctx context.Context) BusinessError
 	StackTrace() string
+	Unwrap() error
+	Details() *ErrorDetails
+	WithFieldViolation(field, description string) BusinessError
+	WithRetryAfter(retryAfter time.Duration) BusinessError
+	WithResourceInfo(resourceType, resourceID string) BusinessError
+	WithExtra(key string, value any) BusinessError
+	RetryClass() utils.RetryClass
+	WithRetryClass(class utils.RetryClass) BusinessError
+	Params() map[string]any
+	WithParam(key string, value any) BusinessError
+	BizData() *biz2.BizData
+	Remote() *RemoteInfo
+	WithRemote(remote *RemoteInfo) BusinessError
 }
 
 // businessError 实现 BusinessError 接口
@@ -26,6 +42,11 @@ type businessError struct {
 	message    string
 	stackTrace string
 	ctx        context.Context
+	cause      error
+	details    *ErrorDetails
+	retryClass *utils.RetryClass
+	params     map[string]any
+	remote     *RemoteInfo
 }
 
 // NewBusinessError 创建新的业务错误
@@ -36,21 +57,40 @@ func NewBusinessError(code ErrorCode, message string) BusinessError {
 	}
 }
 
+// Wrap 使用指定错误码包装底层错误，保留原始错误作为 cause，err 为 nil 时返回 nil
+func Wrap(err error, code ErrorCode, message string) BusinessError {
+	if err == nil {
+		return nil
+	}
+	return &businessError{
+		code:    code,
+		message: message,
+		cause:   err,
+	}
+}
+
 // Error 实现 error 接口
 func (e *businessError) Error() string {
-	var basic, ctxStr, stack string
+	var basic, ctxStr, remote, stack, cause string
 	if e.stackTrace != "" {
 		stack = fmt.Sprintf("stack:\n%s\n ", e.stackTrace)
 	}
 
-	if e.ctx != nil {
-		bizData := biz2.GetBizData(e.ctx)
+	if bizData := biz2.GetBizData(e.ctx); bizData != nil {
 		ctxStr = fmt.Sprintf("ctx:%v ", bizData.String())
 	}
 
+	if e.remote != nil {
+		remote = fmt.Sprintf("remote:[%s][%s][%d] ", e.remote.Service, e.remote.LogID, e.remote.StatusCode)
+	}
+
+	if e.cause != nil {
+		cause = fmt.Sprintf("cause:%s", e.cause.Error())
+	}
+
 	basic = fmt.Sprintf("code:%s, message:`%s` ", e.code, e.message)
 
-	return fmt.Sprintf("{%s%s%s}", basic, ctxStr, stack)
+	return fmt.Sprintf("{%s%s%s%s%s}", basic, ctxStr, remote, stack, cause)
 }
 
 func (e *businessError) Message() string {
@@ -62,35 +102,42 @@ func (e *businessError) Code() ErrorCode {
 	return e.code
 }
 
-// Is 实现错误比较
+// Is 实现错误比较，错误码相同即视为相等，配合 errors.Is 可穿透多层包装
 func (e *businessError) Is(target error) bool {
-	t, ok := target.(*businessError)
+	t, ok := target.(BusinessError)
 	if !ok {
 		return false
 	}
-	return e.code == t.code
+	return e.code == t.Code()
 }
 
-// WithMessage 创建一个新的错误，保持原始错误码但使用新的错误消息
+// Unwrap 返回被包装的底层错误
+func (e *businessError) Unwrap() error {
+	return e.cause
+}
+
+// WithMessage 创建一个新的错误，保持原始错误码、调用栈、ctx 与 cause，但使用新的错误消息
 func (e *businessError) WithMessage(message string) BusinessError {
-	return &businessError{
-		code:    e.code,
-		message: message,
-	}
+	newErr := e.clone()
+	newErr.message = message
+	return newErr
 }
 
-// WithStack 附加调用栈信息
+// WithStack 创建一个新的错误并附加调用栈信息与 ctx
 func (e *businessError) WithStack(ctx context.Context) BusinessError {
-	if ctx != nil {
-		e.ctx = ctx
+	if ctx == nil {
+		ctx = e.ctx
 	}
 
-	stackTrace := getStackTrace()
-	return &businessError{
-		code:       e.code,
-		message:    e.message,
-		stackTrace: stackTrace,
-	}
+	newErr := e.clone()
+	newErr.stackTrace = getStackTrace()
+	newErr.ctx = ctx
+	return newErr
+}
+
+// BizData 返回 WithStack 时 ctx 中的业务数据，未设置时返回 nil
+func (e *businessError) BizData() *biz2.BizData {
+	return biz2.GetBizData(e.ctx)
 }
 
 // StackTrace 返回调用栈信息
@@ -98,6 +145,60 @@ func (e *businessError) StackTrace() string {
 	return e.stackTrace
 }
 
+// RetryClass 返回重试分类，未单独指定时按错误码注册表决定
+func (e *businessError) RetryClass() utils.RetryClass {
+	if e.retryClass != nil {
+		return *e.retryClass
+	}
+	return GetCodeMeta(e.code).RetryClass
+}
+
+// WithRetryClass 创建一个新的错误并指定重试分类，覆盖错误码默认分类
+func (e *businessError) WithRetryClass(class utils.RetryClass) BusinessError {
+	newErr := e.clone()
+	newErr.retryClass = &class
+	return newErr
+}
+
+// RetryAfter 返回 details 中建议的重试等待时长，实现 utils.RetryAfterHinter
+func (e *businessError) RetryAfter() time.Duration {
+	if e.details == nil {
+		return 0
+	}
+	return e.details.RetryInfo.RetryAfter()
+}
+
+// Params 返回消息模板参数
+func (e *businessError) Params() map[string]any {
+	return e.params
+}
+
+// WithParam 创建一个新的错误并设置消息模板参数，用于 MessageCatalog 渲染本地化消息
+func (e *businessError) WithParam(key string, value any) BusinessError {
+	newErr := e.clone()
+	newErr.params = make(map[string]any, len(e.params)+1)
+	for k, v := range e.params {
+		newErr.params[k] = v
+	}
+	newErr.params[key] = value
+	return newErr
+}
+
+// clone 复制当前错误，details 深拷贝以免派生错误之间互相影响
+func (e *businessError) clone() *businessError {
+	return &businessError{
+		code:       e.code,
+		message:    e.message,
+		stackTrace: e.stackTrace,
+		ctx:        e.ctx,
+		cause:      e.cause,
+		details:    e.details.clone(),
+		retryClass: e.retryClass,
+		params:     e.params,
+		remote:     e.remote,
+	}
+}
+
 // Is 检查两个错误是否相等
 func Is(err, target error) bool {
 	if err == nil {
@@ -106,11 +207,7 @@ func Is(err, target error) bool {
 	if target == nil {
 		return false
 	}
-	be, ok := err.(BusinessError)
-	if !ok {
-		return false
-	}
-	return be.Is(target)
+	return errors.Is(err, target)
 }
 
 // getStackTrace 获取调用栈信息
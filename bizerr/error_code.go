@@ -12,6 +12,8 @@ const (
 	Forbidden     ErrorCode = "Forbidden"
 	InternalError ErrorCode = "InternalError"
 	RateLimited   ErrorCode = "RateLimited"
+	Timeout       ErrorCode = "Timeout"
+	Conflict      ErrorCode = "Conflict"
 	// 可以根据需要添加更多错误码
 )
 
@@ -23,4 +25,6 @@ var (
 	ErrForbidden     = NewBusinessError(Forbidden, "")
 	ErrInternalError = NewBusinessError(InternalError, "")
 	RateLimitedError = NewBusinessError(RateLimited, "")
+	ErrTimeout       = NewBusinessError(Timeout, "")
+	ErrConflict      = NewBusinessError(Conflict, "")
 )
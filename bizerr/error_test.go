@@ -0,0 +1,185 @@
+package bizerr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrap(t *testing.T) {
+	ctx := context.Background()
+
+	inner := Wrap(io.EOF, NotFound, "record not found").WithStack(ctx)
+	outer := Wrap(inner, InternalError, "load user failed")
+
+	assert.True(t, errors.Is(outer, io.EOF))
+	assert.True(t, errors.Is(outer, ErrNotFound))
+	assert.True(t, errors.Is(outer, ErrInternalError))
+	assert.False(t, errors.Is(outer, ErrUnauthorized))
+	assert.True(t, Is(outer, ErrNotFound))
+	assert.Equal(t, inner, errors.Unwrap(outer))
+	assert.Equal(t, io.EOF, errors.Unwrap(errors.Unwrap(outer)))
+
+	var be BusinessError
+	assert.True(t, errors.As(outer, &be))
+	assert.Equal(t, InternalError, be.Code())
+
+	msg := outer.Error()
+	assert.Contains(t, msg, "load user failed")
+	assert.Contains(t, msg, "record not found")
+	assert.Contains(t, msg, io.EOF.Error())
+
+	assert.True(t, errors.Is(outer.WithMessage("retry").WithStack(ctx), io.EOF))
+	assert.Nil(t, Wrap(nil, InternalError, "nothing"))
+}
+
+func TestDetails(t *testing.T) {
+	base := ErrInvalidInput.WithMessage("invalid request")
+	err := base.
+		WithFieldViolation("name", "required").
+		WithFieldViolation("age", "must be positive").
+		WithExtra("hint", "check input")
+	assert.Nil(t, base.Details())
+	assert.Len(t, err.Details().FieldViolations, 2)
+
+	_, body := ToErrorBody(context.Background(), err)
+	data, jsonErr := json.Marshal(body)
+	assert.NoError(t, jsonErr)
+
+	decoded := &ErrorBody{}
+	assert.NoError(t, json.Unmarshal(data, decoded))
+	decodedErr := decoded.ToBusinessError()
+	assert.True(t, errors.Is(decodedErr, ErrInvalidInput))
+	assert.Equal(t, "age", decodedErr.Details().FieldViolations[1].Field)
+	assert.Equal(t, "check input", decodedErr.Details().Extra["hint"])
+
+	rateLimited := RateLimitedError.WithRetryAfter(1500 * time.Millisecond)
+	assert.Equal(t, 1500*time.Millisecond, rateLimited.Details().RetryInfo.RetryAfter())
+
+	notFound := ErrNotFound.WithResourceInfo("user", "u-1")
+	assert.Equal(t, "u-1", notFound.Details().ResourceInfo.ResourceID)
+}
+
+func TestMessageCatalog(t *testing.T) {
+	catalog := NewMessageCatalog("en")
+	assert.NoError(t, catalog.Add("en", NotFound, "{{.resource}} not found"))
+	assert.NoError(t, catalog.Add("zh", NotFound, "{{.resource}} 不存在"))
+
+	err := ErrNotFound.WithMessage("user u-1 not found").WithParam("resource", "user")
+	message, ok := catalog.Localize(err.Code(), "zh_CN", err.Params())
+	assert.True(t, ok)
+	assert.Equal(t, "user 不存在", message)
+
+	message, ok = catalog.Localize(err.Code(), "ja", err.Params())
+	assert.True(t, ok)
+	assert.Equal(t, "user not found", message)
+
+	_, ok = catalog.Localize(InvalidInput, "en", nil)
+	assert.False(t, ok)
+
+	assert.Equal(t, "user u-1 not found", LocalizeMessage(err, ""))
+	assert.Equal(t, "请求参数不合法", LocalizeMessage(ErrInvalidInput, "zh-TW"))
+	assert.Equal(t, "bad age", LocalizeMessage(ErrInvalidInput.WithMessage("bad age"), "zh"))
+	assert.Equal(t, "请求的资源不存在", LocalizeMessage(ErrNotFound.WithMessage("x").WithParam("id", 1), "zh"))
+}
+
+func TestMultiError(t *testing.T) {
+	multi := NewMultiError()
+	assert.Nil(t, multi.ErrorOrNil())
+
+	multi.Add(0, "a", nil).
+		Add(1, "b", ErrNotFound.WithMessage("b not found")).
+		Add(2, "c", ErrInvalidInput.WithFieldViolation("name", "required"))
+	err := multi.ErrorOrNil()
+	assert.Equal(t, 2, multi.Len())
+	assert.Equal(t, InvalidInput, multi.Code())
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.True(t, errors.Is(err, ErrInvalidInput))
+	assert.False(t, errors.Is(err, ErrForbidden))
+
+	custom := NewMultiError(NotFound, InvalidInput).
+		Add(0, "a", ErrInvalidInput).
+		Add(1, "b", ErrNotFound)
+	assert.Equal(t, NotFound, custom.Code())
+
+	multi.Add(3, "d", io.EOF)
+	assert.Equal(t, InternalError, multi.Code())
+
+	status, body := ToErrorBody(context.Background(), multi)
+	assert.Equal(t, 500, status)
+	assert.Equal(t, InternalError, body.Code)
+	assert.Len(t, body.Errors, 3)
+	assert.Equal(t, "b", body.Errors[0].Key)
+	assert.Equal(t, NotFound, body.Errors[0].Code)
+	assert.Equal(t, "name", body.Errors[1].Details.FieldViolations[0].Field)
+	assert.Equal(t, 3, body.Errors[2].Index)
+	assert.Empty(t, body.Errors[2].Message)
+}
+
+func TestNamespace(t *testing.T) {
+	userNS, err := RegisterNamespace("test-user-service", 100000, 199999)
+	assert.NoError(t, err)
+
+	_, err = RegisterNamespace("test-user-service", 300000, 399999)
+	assert.Error(t, err)
+	_, err = RegisterNamespace("test-order-service", 150000, 250000)
+	assert.Error(t, err)
+
+	errUserBanned := userNS.MustRegister("TestUserBanned", 100001, CodeMeta{HTTPStatus: 403, Description: "user is banned"})
+	assert.Equal(t, 403, GetCodeMeta(errUserBanned.Code()).HTTPStatus)
+	assert.Equal(t, 100001, GetCodeMeta(errUserBanned.Code()).NumericID)
+
+	assert.Error(t, userNS.Register("TestUserBanned", 100002, CodeMeta{}))
+	assert.Error(t, userNS.Register("TestUserFrozen", 100001, CodeMeta{}))
+	assert.Error(t, userNS.Register("TestUserFrozen", 200001, CodeMeta{}))
+	assert.Error(t, userNS.Register(NotFound, 100003, CodeMeta{}))
+
+	// only namespaces from RegisterNamespace can register codes
+	assert.Error(t, (&Namespace{Name: "test-unregistered", Min: 500000, Max: 599999}).Register("TestOrphan", 500001, CodeMeta{}))
+	assert.Error(t, (&Namespace{Name: "test-user-service", Min: 0, Max: 999999}).Register("TestOrphan", 500001, CodeMeta{}))
+	_, ok := LookupCode("TestOrphan")
+	assert.False(t, ok)
+
+	buffer := &strings.Builder{}
+	assert.NoError(t, DumpRegistry(buffer, DumpFormatMarkdown))
+	assert.Contains(t, buffer.String(), "| TestUserBanned | 100001 | test-user-service | 403 | retryable | user is banned |")
+
+	buffer.Reset()
+	assert.NoError(t, DumpRegistry(buffer, DumpFormatJSON))
+	var entries []*CatalogEntry
+	assert.NoError(t, json.Unmarshal([]byte(buffer.String()), &entries))
+	assert.Equal(t, Unknown, entries[0].Code)
+	assert.Equal(t, ErrorCode("TestUserBanned"), entries[len(entries)-1].Code)
+
+	assert.Error(t, RegisterCode(NotFound, CodeMeta{HTTPStatus: 418}))
+	assert.Equal(t, 404, GetCodeMeta(NotFound).HTTPStatus)
+	assert.Error(t, RegisterCode("TestUserFrozen", CodeMeta{Namespace: "test-user-service", NumericID: 100001}))
+	assert.Error(t, RegisterCode("TestUserFrozen", CodeMeta{Namespace: "test-missing", NumericID: 1}))
+	assert.NoError(t, RegisterCode("TestUserFrozen", CodeMeta{Namespace: "test-user-service", NumericID: 100002}))
+	assert.Equal(t, "test-user-service", GetCodeMeta("TestUserFrozen").Namespace)
+}
+
+func TestRunDumpCommand(t *testing.T) {
+	buffer := &strings.Builder{}
+	assert.NoError(t, RunDumpCommand(nil, buffer))
+	assert.Contains(t, buffer.String(), "| NotFound | 2 | common | 404 | permanent |")
+
+	output := filepath.Join(t.TempDir(), "errors.json")
+	assert.NoError(t, RunDumpCommand([]string{"-format", "json", "-o", output}, io.Discard))
+	data, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	var entries []*CatalogEntry
+	assert.NoError(t, json.Unmarshal(data, &entries))
+	assert.Equal(t, Unknown, entries[0].Code)
+
+	assert.Error(t, RunDumpCommand([]string{"-format", "yaml"}, io.Discard))
+	assert.Error(t, RunDumpCommand([]string{"-unknown"}, io.Discard))
+}
@@ -0,0 +1,287 @@
+package errsink
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/biz"
+	"github.com/ragpanda/go-toolkit/bizerr"
+)
+
+// AggregatorConfig 错误聚合配置
+type AggregatorConfig struct {
+	// WindowSec 单个统计窗口的长度，单位秒
+	WindowSec int64 `yaml:"WindowSec" json:"WindowSec"`
+	// WindowCount 保留的统计窗口个数
+	WindowCount int `yaml:"WindowCount" json:"WindowCount"`
+	// TopFrames 参与指纹计算的栈顶帧数
+	TopFrames int `yaml:"TopFrames" json:"TopFrames"`
+	// MaxGroups 最多保留的指纹数，超出时淘汰最久未出现的指纹
+	MaxGroups int `yaml:"MaxGroups" json:"MaxGroups"`
+}
+
+// Aggregator 按错误码与栈顶帧对错误做指纹聚合，统计各时间窗口内的出现次数并保留最近一次样本
+type Aggregator struct {
+	config AggregatorConfig
+	lock   sync.Mutex
+	groups map[string]*group
+	now    func() time.Time
+}
+
+// Sample 最近一次出现的错误样本
+type Sample struct {
+	Time       time.Time    `json:"time"`
+	Message    string       `json:"message"`
+	Error      string       `json:"error"`
+	StackTrace string       `json:"stack_trace"`
+	BizData    *biz.BizData `json:"biz_data,omitempty"`
+}
+
+// WindowCount 单个时间窗口内的出现次数
+type WindowCount struct {
+	Start time.Time `json:"start"`
+	Count int64     `json:"count"`
+}
+
+// GroupReport 单个指纹的聚合结果
+type GroupReport struct {
+	Fingerprint string           `json:"fingerprint"`
+	Code        bizerr.ErrorCode `json:"code"`
+	TopFrames   []string         `json:"top_frames"`
+	FirstSeen   time.Time        `json:"first_seen"`
+	LastSeen    time.Time        `json:"last_seen"`
+	Total       int64            `json:"total"`
+	// Recent 当前窗口内的出现次数
+	Recent int64 `json:"recent"`
+	// PreviousAvg 之前各窗口的平均出现次数，与 Recent 对比可判断是否突增
+	PreviousAvg float64        `json:"previous_avg"`
+	Windows     []*WindowCount `json:"windows"`
+	Sample      *Sample        `json:"sample"`
+}
+
+type group struct {
+	fingerprint string
+	code        bizerr.ErrorCode
+	topFrames   []string
+	firstSeen   time.Time
+	lastSeen    time.Time
+	total       int64
+	windows     []*WindowCount
+	sample      *Sample
+}
+
+// NewAggregator 创建错误聚合器，config 为 nil 时使用默认配置
+func NewAggregator(config *AggregatorConfig) *Aggregator {
+	a := &Aggregator{
+		groups: make(map[string]*group),
+		now:    time.Now,
+	}
+	if config != nil {
+		a.config = *config
+	}
+	if a.config.WindowSec <= 0 {
+		a.config.WindowSec = 60
+	}
+	if a.config.WindowCount <= 0 {
+		a.config.WindowCount = 60
+	}
+	if a.config.TopFrames <= 0 {
+		a.config.TopFrames = 5
+	}
+	if a.config.MaxGroups <= 0 {
+		a.config.MaxGroups = 1000
+	}
+	return a
+}
+
+// Record 记录一次错误，非 BusinessError 按 InternalError 统计
+func (a *Aggregator) Record(err error) {
+	if err == nil {
+		return
+	}
+
+	code := bizerr.InternalError
+	var message, stackTrace string
+	var bizData *biz.BizData
+	var be bizerr.BusinessError
+	if errors.As(err, &be) {
+		code = be.Code()
+		message = be.Message()
+		stackTrace = be.StackTrace()
+		if d := be.BizData(); d != nil {
+			bizData = d.DeepCopy()
+		}
+	}
+	frames := topFrames(stackTrace, a.config.TopFrames)
+	fingerprint := makeFingerprint(code, frames)
+
+	now := a.now()
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	g, ok := a.groups[fingerprint]
+	if !ok {
+		if len(a.groups) >= a.config.MaxGroups {
+			a.evictOldest()
+		}
+		g = &group{
+			fingerprint: fingerprint,
+			code:        code,
+			topFrames:   frames,
+			firstSeen:   now,
+		}
+		a.groups[fingerprint] = g
+	}
+
+	g.lastSeen = now
+	g.total++
+	a.windowOf(g, now).Count++
+	g.sample = &Sample{
+		Time:       now,
+		Message:    message,
+		Error:      err.Error(),
+		StackTrace: stackTrace,
+		BizData:    bizData,
+	}
+}
+
+// Report 返回所有指纹的聚合结果，按当前窗口出现次数、总次数降序
+func (a *Aggregator) Report() []*GroupReport {
+	now := a.now()
+	current := a.windowStart(now)
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	reports := make([]*GroupReport, 0, len(a.groups))
+	for _, g := range a.groups {
+		a.expireWindows(g, now)
+		report := &GroupReport{
+			Fingerprint: g.fingerprint,
+			Code:        g.code,
+			TopFrames:   append([]string{}, g.topFrames...),
+			FirstSeen:   g.firstSeen,
+			LastSeen:    g.lastSeen,
+			Total:       g.total,
+			Windows:     make([]*WindowCount, 0, len(g.windows)),
+		}
+		var previous int64
+		for _, w := range g.windows {
+			report.Windows = append(report.Windows, &WindowCount{Start: w.Start, Count: w.Count})
+			if w.Start.Equal(current) {
+				report.Recent = w.Count
+			} else {
+				previous += w.Count
+			}
+		}
+		if a.config.WindowCount > 1 {
+			report.PreviousAvg = float64(previous) / float64(a.config.WindowCount-1)
+		}
+		if g.sample != nil {
+			sample := *g.sample
+			report.Sample = &sample
+		}
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Recent != reports[j].Recent {
+			return reports[i].Recent > reports[j].Recent
+		}
+		return reports[i].Total > reports[j].Total
+	})
+	return reports
+}
+
+// Reset 清空所有聚合数据
+func (a *Aggregator) Reset() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.groups = make(map[string]*group)
+}
+
+// CollectMW 收集 handler 通过 c.Error 上报的错误
+func (a *Aggregator) CollectMW() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		for _, e := range c.Errors {
+			a.Record(e.Err)
+		}
+	}
+}
+
+// Handler 以 JSON 输出聚合结果，可挂载到 gin engine 上
+func (a *Aggregator) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, a.Report())
+	}
+}
+
+func (a *Aggregator) windowStart(t time.Time) time.Time {
+	return t.Truncate(time.Duration(a.config.WindowSec) * time.Second)
+}
+
+func (a *Aggregator) windowOf(g *group, now time.Time) *WindowCount {
+	a.expireWindows(g, now)
+	start := a.windowStart(now)
+	if n := len(g.windows); n > 0 && g.windows[n-1].Start.Equal(start) {
+		return g.windows[n-1]
+	}
+	w := &WindowCount{Start: start}
+	g.windows = append(g.windows, w)
+	return w
+}
+
+func (a *Aggregator) expireWindows(g *group, now time.Time) {
+	windowSize := time.Duration(a.config.WindowSec) * time.Second
+	oldest := a.windowStart(now).Add(-time.Duration(a.config.WindowCount-1) * windowSize)
+	idx := 0
+	for idx < len(g.windows) && g.windows[idx].Start.Before(oldest) {
+		idx++
+	}
+	g.windows = g.windows[idx:]
+}
+
+func (a *Aggregator) evictOldest() {
+	var oldest *group
+	for _, g := range a.groups {
+		if oldest == nil || g.lastSeen.Before(oldest.lastSeen) {
+			oldest = g
+		}
+	}
+	if oldest != nil {
+		delete(a.groups, oldest.fingerprint)
+	}
+}
+
+// topFrames 从 bizerr 的调用栈文本中取出栈顶 n 个函数名，忽略行号以免代码变动导致指纹变化
+func topFrames(stackTrace string, n int) []string {
+	frames := make([]string, 0, n)
+	for _, line := range strings.Split(stackTrace, "\n") {
+		if len(frames) >= n {
+			break
+		}
+		if line == "" || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		frames = append(frames, line)
+	}
+	return frames
+}
+
+func makeFingerprint(code bizerr.ErrorCode, frames []string) string {
+	h := sha1.New()
+	h.Write([]byte(code))
+	for _, frame := range frames {
+		h.Write([]byte("|"))
+		h.Write([]byte(frame))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,69 @@
+package errsink
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/biz"
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadUser(ctx context.Context) error {
+	return bizerr.ErrNotFound.WithMessage("user not found").WithStack(ctx)
+}
+
+func saveUser(ctx context.Context) error {
+	return bizerr.ErrNotFound.WithMessage("user not found").WithStack(ctx)
+}
+
+func TestAggregator(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := NewAggregator(&AggregatorConfig{WindowSec: 60, WindowCount: 3})
+	a.now = func() time.Time { return now }
+
+	bizData := biz.NewBizData()
+	bizData.LogID = "log-1"
+	ctx := biz.SetBizData(context.Background(), bizData)
+
+	for i := 0; i < 3; i++ {
+		a.Record(loadUser(ctx))
+	}
+	a.Record(saveUser(ctx))
+	a.Record(errors.New("raw error"))
+
+	now = now.Add(time.Minute)
+	a.Record(saveUser(ctx))
+	a.Record(saveUser(ctx))
+
+	reports := a.Report()
+	assert.Len(t, reports, 3)
+	assert.Equal(t, int64(2), reports[0].Recent)
+	assert.Equal(t, int64(3), reports[0].Total)
+	assert.Equal(t, bizerr.NotFound, reports[0].Code)
+	assert.Contains(t, reports[0].TopFrames[0], "saveUser")
+	assert.Equal(t, "log-1", reports[0].Sample.BizData.LogID)
+	assert.Equal(t, 0.5, reports[0].PreviousAvg)
+
+	now = now.Add(3 * time.Minute)
+	for _, report := range a.Report() {
+		assert.Empty(t, report.Windows)
+		assert.Equal(t, int64(0), report.Recent)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/debug/errors", a.Handler())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/debug/errors", nil))
+	var decoded []*GroupReport
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, utils.Unmarshal(w.Body.Bytes(), &decoded))
+	assert.Len(t, decoded, 3)
+}
@@ -0,0 +1,130 @@
+package bizerr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultCodePrecedence 定义 MultiError 推导整体错误码时的默认优先级，越靠前优先级越高
+var DefaultCodePrecedence = []ErrorCode{
+	InternalError,
+	Unknown,
+	Timeout,
+	Unauthorized,
+	Forbidden,
+	Conflict,
+	RateLimited,
+	InvalidInput,
+	NotFound,
+}
+
+// ItemError 批量操作中单个条目的错误，Index 为条目下标，Key 为条目标识（如 ID）
+type ItemError struct {
+	Index int
+	Key   string
+	Err   error
+}
+
+// MultiError 收集批量操作中多个条目的错误
+type MultiError struct {
+	items      []*ItemError
+	precedence []ErrorCode
+}
+
+// NewMultiError 创建批量错误，precedence 为空时使用 DefaultCodePrecedence
+func NewMultiError(precedence ...ErrorCode) *MultiError {
+	if len(precedence) == 0 {
+		precedence = DefaultCodePrecedence
+	}
+	return &MultiError{precedence: precedence}
+}
+
+// Add 添加一个条目错误，err 为 nil 时忽略
+func (m *MultiError) Add(index int, key string, err error) *MultiError {
+	if err == nil {
+		return m
+	}
+	m.items = append(m.items, &ItemError{Index: index, Key: key, Err: err})
+	return m
+}
+
+// Items 返回所有条目错误
+func (m *MultiError) Items() []*ItemError {
+	return m.items
+}
+
+// Len 返回条目错误个数
+func (m *MultiError) Len() int {
+	return len(m.items)
+}
+
+// ErrorOrNil 没有条目错误时返回 nil，便于直接作为函数返回值
+func (m *MultiError) ErrorOrNil() error {
+	if m == nil || len(m.items) == 0 {
+		return nil
+	}
+	return m
+}
+
+// Code 按优先级推导整体错误码，非 BusinessError 视为 InternalError，优先级中未列出的错误码排在最后
+func (m *MultiError) Code() ErrorCode {
+	var result ErrorCode
+	resultRank := -1
+	for _, item := range m.items {
+		code := itemCode(item.Err)
+		rank := len(m.precedence)
+		for i, c := range m.precedence {
+			if c == code {
+				rank = i
+				break
+			}
+		}
+		if resultRank == -1 || rank < resultRank {
+			result, resultRank = code, rank
+		}
+	}
+	if resultRank == -1 {
+		return Unknown
+	}
+	return result
+}
+
+// Error 实现 error 接口
+func (m *MultiError) Error() string {
+	parts := make([]string, 0, len(m.items))
+	for _, item := range m.items {
+		parts = append(parts, fmt.Sprintf("[%d:%s] %s", item.Index, item.Key, item.Err.Error()))
+	}
+	return fmt.Sprintf("{code:%s, %d items failed: %s}", m.Code(), len(m.items), strings.Join(parts, "; "))
+}
+
+// Is 任一条目错误与 target 匹配即返回 true，target 为 BusinessError 时也与整体错误码比较
+func (m *MultiError) Is(target error) bool {
+	if t, ok := target.(BusinessError); ok && t.Code() == m.Code() {
+		return true
+	}
+	for _, item := range m.items {
+		if errors.Is(item.Err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap 返回所有条目错误
+func (m *MultiError) Unwrap() []error {
+	errs := make([]error, 0, len(m.items))
+	for _, item := range m.items {
+		errs = append(errs, item.Err)
+	}
+	return errs
+}
+
+func itemCode(err error) ErrorCode {
+	var be BusinessError
+	if errors.As(err, &be) {
+		return be.Code()
+	}
+	return InternalError
+}
@@ -0,0 +1,115 @@
+package bizerr
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Namespace 错误码命名空间，占用一段数字编号范围，如 user-service 占用 [100000, 199999]
+type Namespace struct {
+	Name string
+	Min  int
+	Max  int
+}
+
+var (
+	namespaceLock sync.Mutex
+	namespaces    = map[string]*Namespace{
+		CommonNamespace: {Name: CommonNamespace, Min: 0, Max: 99999},
+	}
+)
+
+// RegisterNamespace 注册命名空间，名称重复或编号范围与已有命名空间重叠时返回错误
+func RegisterNamespace(name string, min, max int) (*Namespace, error) {
+	if name == "" || min > max {
+		return nil, ErrInvalidInput.WithMessage(fmt.Sprintf("invalid namespace %q [%d, %d]", name, min, max))
+	}
+
+	namespaceLock.Lock()
+	defer namespaceLock.Unlock()
+
+	if _, ok := namespaces[name]; ok {
+		return nil, ErrInvalidInput.WithMessage(fmt.Sprintf("namespace %q already registered", name))
+	}
+	for _, ns := range namespaces {
+		if min <= ns.Max && ns.Min <= max {
+			return nil, ErrInvalidInput.WithMessage(fmt.Sprintf(
+				"namespace %q [%d, %d] overlaps with %q [%d, %d]", name, min, max, ns.Name, ns.Min, ns.Max))
+		}
+	}
+
+	ns := &Namespace{Name: name, Min: min, Max: max}
+	namespaces[name] = ns
+	return ns, nil
+}
+
+// MustRegisterNamespace 同 RegisterNamespace，失败时 panic，用于 init 阶段
+func MustRegisterNamespace(name string, min, max int) *Namespace {
+	ns, err := RegisterNamespace(name, min, max)
+	if err != nil {
+		panic(err)
+	}
+	return ns
+}
+
+// Register 在命名空间内注册错误码，命名空间未通过 RegisterNamespace 注册、数字编号超出范围、
+// 错误码或数字编号重复时返回错误
+func (ns *Namespace) Register(code ErrorCode, numericID int, meta CodeMeta) error {
+	namespaceLock.Lock()
+	registered, ok := namespaces[ns.Name]
+	namespaceLock.Unlock()
+	if !ok || *registered != *ns {
+		return ErrInvalidInput.WithMessage(fmt.Sprintf(
+			"namespace %q [%d, %d] of %s not registered", ns.Name, ns.Min, ns.Max, code))
+	}
+	if numericID < ns.Min || numericID > ns.Max {
+		return ErrInvalidInput.WithMessage(fmt.Sprintf(
+			"numeric id %d of %s out of namespace %q [%d, %d]", numericID, code, ns.Name, ns.Min, ns.Max))
+	}
+
+	codeRegistryLock.Lock()
+	defer codeRegistryLock.Unlock()
+
+	if _, ok := codeRegistry[code]; ok {
+		return ErrInvalidInput.WithMessage(fmt.Sprintf("error code %s already registered", code))
+	}
+	for existCode, existMeta := range codeRegistry {
+		if existMeta.Namespace != "" && existMeta.NumericID == numericID {
+			return ErrInvalidInput.WithMessage(fmt.Sprintf(
+				"numeric id %d of %s already used by %s", numericID, code, existCode))
+		}
+	}
+
+	meta.Namespace = ns.Name
+	meta.NumericID = numericID
+	codeRegistry[code] = meta
+	return nil
+}
+
+// MustRegister 同 Register，失败时 panic，返回该错误码的预定义错误，用于 init 阶段定义错误变量
+//
+//	var userNS = bizerr.MustRegisterNamespace("user-service", 100000, 199999)
+//	var ErrUserBanned = userNS.MustRegister("UserBanned", 100001, bizerr.CodeMeta{HTTPStatus: 403})
+func (ns *Namespace) MustRegister(code ErrorCode, numericID int, meta CodeMeta) BusinessError {
+	if err := ns.Register(code, numericID, meta); err != nil {
+		panic(err)
+	}
+	return NewBusinessError(code, "")
+}
+
+// ListNamespaces 返回所有已注册的命名空间，按编号范围排序
+func ListNamespaces() []*Namespace {
+	namespaceLock.Lock()
+	defer namespaceLock.Unlock()
+
+	result := make([]*Namespace, 0, len(namespaces))
+	for _, ns := range namespaces {
+		copied := *ns
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Min < result[j].Min
+	})
+	return result
+}
@@ -0,0 +1,82 @@
+package bizerr
+
+import (
+	"context"
+	"net/http"
+)
+
+// ProblemContentType RFC 7807 定义的错误响应 Content-Type
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypeBaseURI 不为空时，problem 文档的 type 字段为 ProblemTypeBaseURI + 错误码，否则为 about:blank
+var ProblemTypeBaseURI = ""
+
+// ProblemDetails 定义 RFC 7807 错误文档，code、log_id、details 为扩展字段
+type ProblemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	Code    ErrorCode     `json:"code,omitempty"`
+	LogID   string        `json:"log_id,omitempty"`
+	Details *ErrorDetails `json:"details,omitempty"`
+}
+
+// ToProblemDetails 将任意错误转换为 RFC 7807 文档，instance 一般为请求路径
+func ToProblemDetails(ctx context.Context, err error, instance string) *ProblemDetails {
+	status, body := ToErrorBody(ctx, err)
+	problem := &ProblemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   body.Message,
+		Instance: instance,
+		Code:     body.Code,
+		LogID:    body.LogID,
+		Details:  body.Details,
+	}
+	if ProblemTypeBaseURI != "" {
+		problem.Type = ProblemTypeBaseURI + string(body.Code)
+	}
+	return problem
+}
+
+// ToBusinessError 将 RFC 7807 文档还原为 BusinessError，缺少 code 扩展字段时按 HTTP 状态码推断
+func (p *ProblemDetails) ToBusinessError() BusinessError {
+	code := p.Code
+	if code == "" {
+		code = codeFromHTTPStatus(p.Status)
+	}
+	message := p.Detail
+	if message == "" {
+		message = p.Title
+	}
+	return &businessError{
+		code:    code,
+		message: message,
+		details: p.Details.clone(),
+	}
+}
+
+func codeFromHTTPStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return InvalidInput
+	case http.StatusUnauthorized:
+		return Unauthorized
+	case http.StatusForbidden:
+		return Forbidden
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusConflict:
+		return Conflict
+	case http.StatusTooManyRequests:
+		return RateLimited
+	case http.StatusGatewayTimeout:
+		return Timeout
+	default:
+		return InternalError
+	}
+}
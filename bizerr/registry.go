@@ -0,0 +1,138 @@
+package bizerr
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/ragpanda/go-toolkit/log/consts"
+	"github.com/ragpanda/go-toolkit/utils"
+)
+
+// CodeMeta 描述错误码对应的 HTTP 状态码、日志级别与重试分类
+type CodeMeta struct {
+	HTTPStatus int
+	LogLevel   consts.LogLevel
+	RetryClass utils.RetryClass
+
+	// Namespace 错误码所属命名空间，NumericID 为命名空间范围内的数字编号，通过 Namespace.Register 注册时填充
+	Namespace string
+	NumericID int
+	// Description 错误码说明，用于导出错误码目录
+	Description string
+	// AlwaysLocalize 为 true 时错误带有自定义消息也按消息目录渲染
+	AlwaysLocalize bool
+}
+
+// CommonNamespace 内置错误码所在的命名空间
+const CommonNamespace = "common"
+
+var (
+	codeRegistryLock sync.RWMutex
+	codeRegistry     = map[ErrorCode]CodeMeta{
+		Unknown: {
+			HTTPStatus: http.StatusInternalServerError, LogLevel: consts.LogLevelError,
+			Namespace: CommonNamespace, NumericID: 1, Description: "Unknown error",
+		},
+		NotFound: {
+			HTTPStatus: http.StatusNotFound, LogLevel: consts.LogLevelInfo, RetryClass: utils.RetryClassPermanent,
+			Namespace: CommonNamespace, NumericID: 2, Description: "The requested resource does not exist",
+		},
+		InvalidInput: {
+			HTTPStatus: http.StatusBadRequest, LogLevel: consts.LogLevelWarn, RetryClass: utils.RetryClassPermanent,
+			Namespace: CommonNamespace, NumericID: 3, Description: "The request parameters are invalid",
+		},
+		Unauthorized: {
+			HTTPStatus: http.StatusUnauthorized, LogLevel: consts.LogLevelWarn, RetryClass: utils.RetryClassPermanent,
+			Namespace: CommonNamespace, NumericID: 4, Description: "The request is not authenticated",
+		},
+		Forbidden: {
+			HTTPStatus: http.StatusForbidden, LogLevel: consts.LogLevelWarn, RetryClass: utils.RetryClassPermanent,
+			Namespace: CommonNamespace, NumericID: 5, Description: "The caller has no permission for the operation",
+		},
+		InternalError: {
+			HTTPStatus: http.StatusInternalServerError, LogLevel: consts.LogLevelError,
+			Namespace: CommonNamespace, NumericID: 6, Description: "Internal server error",
+		},
+		RateLimited: {
+			HTTPStatus: http.StatusTooManyRequests, LogLevel: consts.LogLevelWarn, RetryClass: utils.RetryClassThrottled,
+			Namespace: CommonNamespace, NumericID: 7, Description: "Too many requests",
+		},
+		Timeout: {
+			HTTPStatus: http.StatusGatewayTimeout, LogLevel: consts.LogLevelWarn,
+			Namespace: CommonNamespace, NumericID: 8, Description: "The request did not complete in time",
+		},
+		Conflict: {
+			HTTPStatus: http.StatusConflict, LogLevel: consts.LogLevelWarn,
+			Namespace: CommonNamespace, NumericID: 9, Description: "The request conflicts with the current state of the resource",
+		},
+	}
+)
+
+// RegisterCode 注册错误码的元信息，错误码已注册时返回错误；meta.Namespace 非空时按 Namespace.Register 校验数字编号
+//
+// Deprecated: 使用 Namespace.Register / Namespace.MustRegister 注册错误码
+func RegisterCode(code ErrorCode, meta CodeMeta) error {
+	if meta.Namespace != "" {
+		namespaceLock.Lock()
+		ns, ok := namespaces[meta.Namespace]
+		namespaceLock.Unlock()
+		if !ok {
+			return ErrInvalidInput.WithMessage(fmt.Sprintf("namespace %q of %s not registered", meta.Namespace, code))
+		}
+		return ns.Register(code, meta.NumericID, meta)
+	}
+
+	codeRegistryLock.Lock()
+	defer codeRegistryLock.Unlock()
+	if _, ok := codeRegistry[code]; ok {
+		return ErrInvalidInput.WithMessage(fmt.Sprintf("error code %s already registered", code))
+	}
+	codeRegistry[code] = meta
+	return nil
+}
+
+// LookupCode 查询错误码的元信息，未注册时 ok 为 false
+func LookupCode(code ErrorCode) (meta CodeMeta, ok bool) {
+	codeRegistryLock.RLock()
+	defer codeRegistryLock.RUnlock()
+	meta, ok = codeRegistry[code]
+	return meta, ok
+}
+
+// GetCodeMeta 查询错误码的元信息，未注册的错误码按 InternalError 处理
+func GetCodeMeta(code ErrorCode) CodeMeta {
+	if meta, ok := LookupCode(code); ok {
+		return meta
+	}
+	meta, _ := LookupCode(InternalError)
+	return meta
+}
+
+// CodeEntry 错误码及其元信息，用于导出错误码目录
+type CodeEntry struct {
+	Code ErrorCode
+	CodeMeta
+}
+
+// ListCodes 返回所有已注册的错误码，按数字编号排序，未归属命名空间的错误码排在最后
+func ListCodes() []*CodeEntry {
+	codeRegistryLock.RLock()
+	entries := make([]*CodeEntry, 0, len(codeRegistry))
+	for code, meta := range codeRegistry {
+		entries = append(entries, &CodeEntry{Code: code, CodeMeta: meta})
+	}
+	codeRegistryLock.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if (entries[i].Namespace == "") != (entries[j].Namespace == "") {
+			return entries[i].Namespace != ""
+		}
+		if entries[i].NumericID != entries[j].NumericID {
+			return entries[i].NumericID < entries[j].NumericID
+		}
+		return entries[i].Code < entries[j].Code
+	})
+	return entries
+}
@@ -0,0 +1,23 @@
+package bizerr
+
+// RemoteInfo 描述从下游服务响应中还原的错误来源
+type RemoteInfo struct {
+	Service    string `json:"service"`
+	LogID      string `json:"log_id"`
+	StatusCode int    `json:"status_code"`
+}
+
+// Remote 返回错误来源的下游服务信息，本地产生的错误返回 nil
+func (e *businessError) Remote() *RemoteInfo {
+	return e.remote
+}
+
+// WithRemote 创建一个新的错误并记录下游服务信息，仅用于日志与排查，不随错误响应输出
+func (e *businessError) WithRemote(remote *RemoteInfo) BusinessError {
+	newErr := e.clone()
+	if remote != nil {
+		copied := *remote
+		newErr.remote = &copied
+	}
+	return newErr
+}
@@ -0,0 +1,78 @@
+package bizerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	biz2 "github.com/ragpanda/go-toolkit/biz"
+)
+
+// ErrorBody 定义错误响应的标准 JSON 结构
+type ErrorBody struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	LogID   string    `json:"log_id,omitempty"`
+
+	Details *ErrorDetails `json:"details,omitempty"`
+	// Errors 批量操作中各条目的错误，仅 MultiError 输出
+	Errors []*ItemErrorBody `json:"errors,omitempty"`
+}
+
+// ItemErrorBody 定义批量错误中单个条目的错误响应
+type ItemErrorBody struct {
+	Index int    `json:"index"`
+	Key   string `json:"key,omitempty"`
+	ErrorBody
+}
+
+// ToErrorBody 将任意错误转换为 HTTP 状态码与标准错误响应，ctx 中 BizData 指定语言时输出本地化消息
+// 非 BusinessError 或未注册的错误码统一按 InternalError 输出，不透出内部错误信息
+func ToErrorBody(ctx context.Context, err error) (int, *ErrorBody) {
+	var logID, locale string
+	if bizData := biz2.GetBizData(ctx); bizData != nil {
+		logID = bizData.LogID
+		locale = bizData.Locale
+	}
+
+	body := toErrorBody(err, locale)
+	body.LogID = logID
+	return GetCodeMeta(body.Code).HTTPStatus, body
+}
+
+func toErrorBody(err error, locale string) *ErrorBody {
+	var multi *MultiError
+	if errors.As(err, &multi) {
+		be := NewBusinessError(multi.Code(), fmt.Sprintf("%d items failed", multi.Len()))
+		body := toErrorBody(be, locale)
+		for _, item := range multi.Items() {
+			body.Errors = append(body.Errors, &ItemErrorBody{
+				Index:     item.Index,
+				Key:       item.Key,
+				ErrorBody: *toErrorBody(item.Err, locale),
+			})
+		}
+		return body
+	}
+
+	var be BusinessError
+	if !errors.As(err, &be) {
+		be = ErrInternalError
+	} else if _, ok := LookupCode(be.Code()); !ok {
+		be = ErrInternalError
+	}
+	return &ErrorBody{
+		Code:    be.Code(),
+		Message: LocalizeMessage(be, locale),
+		Details: be.Details(),
+	}
+}
+
+// ToBusinessError 将标准错误响应还原为 BusinessError，保留错误码、消息与详情
+func (b *ErrorBody) ToBusinessError() BusinessError {
+	return &businessError{
+		code:    b.Code,
+		message: b.Message,
+		details: b.Details.clone(),
+	}
+}
@@ -0,0 +1,32 @@
+// errcode-dump prints the bizerr error code registry as Markdown or JSON.
+//
+// Only codes registered in this binary are printed. To publish a service's own
+// codes, add a tiny main to the service that blank-imports the packages
+// registering them and calls bizerr.RunDumpCommand, e.g.
+//
+//	import _ "example.com/user-service/errs"
+//
+//	func main() {
+//		if err := bizerr.RunDumpCommand(os.Args[1:], os.Stdout); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// and wire it into go:generate:
+//
+//	//go:generate go run ./cmd/errcode-dump -format markdown -o ERRORS.md
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ragpanda/go-toolkit/bizerr"
+)
+
+func main() {
+	if err := bizerr.RunDumpCommand(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
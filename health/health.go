@@ -0,0 +1,241 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ragpanda/go-toolkit/bizerr"
+)
+
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusDegraded Status = "degraded"
+	StatusFail     Status = "fail"
+	StatusDraining Status = "draining"
+)
+
+const (
+	DefaultCheckTimeout = time.Second
+	DefaultCacheTTL     = 2 * time.Second
+)
+
+type CheckFunc func(ctx context.Context) error
+
+// Check 命名的健康检查，Critical 为 true 时失败会导致 readiness 失败，否则仅标记为 degraded
+type Check struct {
+	Name     string
+	Check    CheckFunc
+	Timeout  time.Duration
+	Critical bool
+}
+
+type CheckResult struct {
+	Status     Status `json:"status"`
+	Critical   bool   `json:"critical"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type Report struct {
+	Status    Status                  `json:"status"`
+	Checks    map[string]*CheckResult `json:"checks,omitempty"`
+	CheckedAt time.Time               `json:"checked_at"`
+}
+
+// OK readiness/liveness 是否通过，degraded 视为通过
+func (r *Report) OK() bool {
+	return r.Status == StatusOK || r.Status == StatusDegraded
+}
+
+// Registry 健康检查注册表，缓存检查结果 cacheTTL，避免探针频繁请求打到下游
+type Registry struct {
+	lock     sync.RWMutex
+	checks   map[string]*Check
+	cacheTTL time.Duration
+
+	refreshLock sync.Mutex
+	cached      atomic.Value // *Report
+	draining    int32
+}
+
+func NewRegistry(cacheTTL time.Duration) *Registry {
+	return &Registry{
+		checks:   make(map[string]*Check),
+		cacheTTL: cacheTTL,
+	}
+}
+
+var global = NewRegistry(DefaultCacheTTL)
+
+// GetGlobal 获取全局注册表，GinHttpServer 默认使用它提供 /readyz
+func GetGlobal() *Registry {
+	return global
+}
+
+// Register 注册到全局注册表
+func Register(check *Check) error {
+	return global.Register(check)
+}
+
+// Register 注册健康检查，名称重复时返回错误
+func (r *Registry) Register(check *Check) error {
+	if check.Name == "" || check.Check == nil {
+		return bizerr.ErrInvalidInput.WithMessage("health check name and func are required")
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.checks[check.Name]; ok {
+		return bizerr.ErrInvalidInput.WithMessage(fmt.Sprintf("health check %q already registered", check.Name))
+	}
+	copied := *check
+	if copied.Timeout <= 0 {
+		copied.Timeout = DefaultCheckTimeout
+	}
+	r.checks[check.Name] = &copied
+	r.cached.Store((*Report)(nil))
+	return nil
+}
+
+// Unregister 移除健康检查
+func (r *Registry) Unregister(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.checks, name)
+	r.cached.Store((*Report)(nil))
+}
+
+// SetCacheTTL 设置检查结果缓存时间，小于等于 0 时每次都执行检查
+func (r *Registry) SetCacheTTL(ttl time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.cacheTTL = ttl
+}
+
+// SetDraining 优雅退出期间置为 true，readiness 随即失败，使负载均衡摘除流量
+func (r *Registry) SetDraining(draining bool) {
+	var v int32
+	if draining {
+		v = 1
+	}
+	atomic.StoreInt32(&r.draining, v)
+}
+
+func (r *Registry) Draining() bool {
+	return atomic.LoadInt32(&r.draining) == 1
+}
+
+// Liveness 进程存活即通过，不执行依赖检查，避免下游故障导致实例被反复重启
+func (r *Registry) Liveness(ctx context.Context) *Report {
+	return &Report{Status: StatusOK, CheckedAt: time.Now()}
+}
+
+// Readiness 执行全部检查并汇总结果，draining 时直接失败
+func (r *Registry) Readiness(ctx context.Context) *Report {
+	if r.Draining() {
+		return &Report{Status: StatusDraining, CheckedAt: time.Now()}
+	}
+
+	r.lock.RLock()
+	ttl := r.cacheTTL
+	r.lock.RUnlock()
+	if report, ok := r.cachedReport(ttl); ok {
+		return report
+	}
+
+	// 同一时刻只刷新一次，其余请求等待后复用结果
+	r.refreshLock.Lock()
+	defer r.refreshLock.Unlock()
+	if report, ok := r.cachedReport(ttl); ok {
+		return report
+	}
+	report := r.Run(ctx)
+	r.cached.Store(report)
+	return report
+}
+
+func (r *Registry) cachedReport(ttl time.Duration) (*Report, bool) {
+	report, ok := r.cached.Load().(*Report)
+	if !ok || report == nil || ttl <= 0 || time.Since(report.CheckedAt) > ttl {
+		return nil, false
+	}
+	return report, true
+}
+
+// Run 并发执行全部检查，不使用缓存
+func (r *Registry) Run(ctx context.Context) *Report {
+	r.lock.RLock()
+	checks := make([]*Check, 0, len(r.checks))
+	for _, check := range r.checks {
+		checks = append(checks, check)
+	}
+	r.lock.RUnlock()
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].Name < checks[j].Name
+	})
+
+	results := make([]*CheckResult, len(checks))
+	wg := sync.WaitGroup{}
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check *Check) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := &Report{
+		Status:    StatusOK,
+		Checks:    make(map[string]*CheckResult, len(checks)),
+		CheckedAt: time.Now(),
+	}
+	for i, check := range checks {
+		result := results[i]
+		report.Checks[check.Name] = result
+		if result.Status == StatusOK {
+			continue
+		}
+		if check.Critical {
+			report.Status = StatusFail
+		} else if report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, check *Check) (result *CheckResult) {
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	result = &CheckResult{Status: StatusOK, Critical: check.Critical}
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- fmt.Errorf("health check panic: %v", recovered)
+			}
+		}()
+		done <- check.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("health check timeout after %v", check.Timeout)
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,49 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadiness(t *testing.T) {
+	ctx := context.Background()
+	r := NewRegistry(time.Hour)
+
+	var calls int32
+	assert.NoError(t, r.Register(&Check{Name: "db", Critical: true, Check: func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}}))
+	assert.NoError(t, r.Register(&Check{Name: "cache", Check: func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}}))
+	assert.Error(t, r.Register(&Check{Name: "db", Check: func(ctx context.Context) error { return nil }}))
+
+	report := r.Readiness(ctx)
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.True(t, report.OK())
+	assert.Equal(t, "connection refused", report.Checks["cache"].Error)
+
+	// 缓存期内不重复执行
+	r.Readiness(ctx)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	assert.NoError(t, r.Register(&Check{Name: "slow", Critical: true, Timeout: 10 * time.Millisecond, Check: func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}}))
+	report = r.Readiness(ctx)
+	assert.Equal(t, StatusFail, report.Status)
+	assert.False(t, report.OK())
+	assert.Contains(t, report.Checks["slow"].Error, "timeout")
+
+	r.SetDraining(true)
+	assert.Equal(t, StatusDraining, r.Readiness(ctx).Status)
+	assert.True(t, r.Liveness(ctx).OK())
+}
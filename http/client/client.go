@@ -5,8 +5,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -15,8 +17,11 @@ import (
 	"github.com/ragpanda/go-toolkit/log"
 	"github.com/ragpanda/go-toolkit/utils"
 	"github.com/ragpanda/go-toolkit/utils/ratelimit"
+	"github.com/ragpanda/go-toolkit/utils/signature"
 )
 
+const logIDHeader = "X-Log-Id"
+
 type HttpClient struct {
 	client        http.Client
 	defaultOption HttpOptionalArgs
@@ -74,6 +79,11 @@ type HttpOptionalArgs struct {
 	MaxRetryTimes        int
 	RetryIntervalMillSec int64
 
+	// ServiceName name of the callee, recorded on errors decoded from its responses, default is the url host
+	ServiceName string
+	// Signer signs every attempt with HMAC-SHA256 if set, see signature.Verifier for the server side
+	Signer *signature.Signer
+
 	GlobalHttpConfig
 }
 
@@ -88,6 +98,13 @@ type GlobalHttpConfig struct {
 }
 type HttpOption func(*HttpOptionalArgs)
 
+// WithSigner signs outgoing requests with the given key id and secret
+func WithSigner(keyID, secret string) HttpOption {
+	return func(args *HttpOptionalArgs) {
+		args.Signer = &signature.Signer{KeyID: keyID, Secret: secret}
+	}
+}
+
 func (self *HttpClient) DoJson(ctx context.Context, urlStr string, body interface{}, httpOptions ...HttpOption) *HttpResultSet {
 	option := self.getDefaultOption()
 	for _, opt := range httpOptions {
@@ -148,11 +165,11 @@ func (self *HttpClient) DoJson(ctx context.Context, urlStr string, body interfac
 		}
 	}
 
+	reqBody := utils.MustJsonEncodeBytes(body)
 	httpReq := &http.Request{
 		Method: option.Method,
 		URL:    urlObj,
 		Header: make(http.Header),
-		Body:   io.NopCloser(bytes.NewBuffer(utils.MustJsonEncodeBytes(body))),
 	}
 
 	for k, v := range option.Header {
@@ -171,7 +188,14 @@ func (self *HttpClient) DoJson(ctx context.Context, urlStr string, body interfac
 		if rateLimit != nil {
 			rateLimit.Take(ctx)
 		}
-		resp, err := self.client.Do(httpReq)
+		httpReq.Body = io.NopCloser(bytes.NewReader(reqBody))
+		if option.Signer != nil {
+			if err := option.Signer.Sign(httpReq, reqBody); err != nil {
+				return err
+			}
+		}
+		// attach ctx so that callers' deadlines and cancellation abort the in-flight request
+		resp, err := self.client.Do(httpReq.WithContext(ctx))
 		if err != nil {
 			log.Warn(ctx, "http request error %s", err.Error())
 			return err
@@ -184,12 +208,7 @@ func (self *HttpClient) DoJson(ctx context.Context, urlStr string, body interfac
 		defer resp.Body.Close()
 
 		result.StatusCode = resp.StatusCode
-		if !option.DisableCheckStatusCode && (resp.StatusCode < 200 || resp.StatusCode > 226) {
-			log.Warn(ctx, "http request status code error %s, %d", urlStr, resp.StatusCode)
-			return bizerr.ErrInternalError.WithMessage(
-				fmt.Sprintf("http response code invalid, url=`%s`, status_code=`%d`", urlStr, resp.StatusCode))
-		}
-
+		result.Header = resp.Header
 		respBody, err := io.ReadAll(resp.Body)
 		if err != nil {
 			log.Warn(ctx, "http read body error %s", err.Error())
@@ -197,6 +216,26 @@ func (self *HttpClient) DoJson(ctx context.Context, urlStr string, body interfac
 		}
 
 		result.Body = respBody
+		if !option.DisableCheckStatusCode && (resp.StatusCode < 200 || resp.StatusCode > 226) {
+			log.Warn(ctx, "http request status code error %s, %d", urlStr, resp.StatusCode)
+			statusErr := bizerr.ErrInternalError.WithMessage(
+				fmt.Sprintf("http response code invalid, url=`%s`, status_code=`%d`", urlStr, resp.StatusCode)).
+				WithRetryClass(utils.ClassifyHTTPStatus(resp.StatusCode))
+			if remoteErr, remoteLogID, ok := result.decodeRemoteError(ctx); ok {
+				statusErr = remoteErr.WithRemote(&bizerr.RemoteInfo{
+					Service:    utils.GetNonEmptyStr(option.ServiceName, urlObj.Host),
+					LogID:      utils.GetNonEmptyStr(remoteLogID, resp.Header.Get(logIDHeader)),
+					StatusCode: resp.StatusCode,
+				})
+				if _, registered := bizerr.LookupCode(statusErr.Code()); !registered {
+					statusErr = statusErr.WithRetryClass(utils.ClassifyHTTPStatus(resp.StatusCode))
+				}
+			}
+			if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After")); retryAfter > 0 {
+				statusErr = statusErr.WithRetryAfter(retryAfter)
+			}
+			return statusErr
+		}
 		return nil
 	}, utils.RetryMaxTimes(option.MaxRetryTimes, time.Duration(option.RetryIntervalMillSec)*time.Millisecond))
 
@@ -209,6 +248,20 @@ func (self *HttpClient) DoJson(ctx context.Context, urlStr string, body interfac
 	return result
 }
 
+// parseRetryAfter parse Retry-After header in either delay-seconds or http-date form
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if sec, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(sec) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
 func (self *HttpClient) getDefaultOption() *HttpOptionalArgs {
 	data := utils.JsonDeepCopy(self.defaultOption)
 	return data
@@ -217,6 +270,7 @@ func (self *HttpClient) getDefaultOption() *HttpOptionalArgs {
 type HttpResultSet struct {
 	Url        string
 	StatusCode int
+	Header     http.Header
 	Body       []byte
 
 	err error
@@ -239,3 +293,59 @@ func (self *HttpResultSet) Unmarshal(ctx context.Context, v interface{}) error {
 
 	return nil
 }
+
+// DecodeProblem turns an application/problem+json response back into a BusinessError,
+// ok is false if the response is not a problem document
+func (self *HttpResultSet) DecodeProblem(ctx context.Context) (err bizerr.BusinessError, ok bool) {
+	problem, ok := self.decodeProblem(ctx)
+	if !ok {
+		return nil, false
+	}
+	return problem.ToBusinessError(), true
+}
+
+// DecodeRemoteError turns an error response rendered by a toolkit service, either the
+// standard error envelope or a problem document, back into a BusinessError with the remote code
+func (self *HttpResultSet) DecodeRemoteError(ctx context.Context) (err bizerr.BusinessError, ok bool) {
+	err, _, ok = self.decodeRemoteError(ctx)
+	return err, ok
+}
+
+func (self *HttpResultSet) decodeRemoteError(ctx context.Context) (err bizerr.BusinessError, logID string, ok bool) {
+	if problem, ok := self.decodeProblem(ctx); ok {
+		return problem.ToBusinessError(), problem.LogID, true
+	}
+	if self.mediaType() != "application/json" {
+		return nil, "", false
+	}
+
+	body := &bizerr.ErrorBody{}
+	if decodeErr := utils.Unmarshal(self.Body, body); decodeErr != nil || body.Code == "" {
+		return nil, "", false
+	}
+	return body.ToBusinessError(), body.LogID, true
+}
+
+func (self *HttpResultSet) decodeProblem(ctx context.Context) (*bizerr.ProblemDetails, bool) {
+	if self.mediaType() != bizerr.ProblemContentType {
+		return nil, false
+	}
+
+	problem := &bizerr.ProblemDetails{}
+	if decodeErr := utils.Unmarshal(self.Body, problem); decodeErr != nil {
+		log.Warn(ctx, "http decode problem error %s, body=`%s`", decodeErr.Error(), self.Body)
+		return nil, false
+	}
+	if problem.Status == 0 {
+		problem.Status = self.StatusCode
+	}
+	return problem, true
+}
+
+func (self *HttpResultSet) mediaType() string {
+	if self.Header == nil || len(self.Body) == 0 {
+		return ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(self.Header.Get("Content-Type"))
+	return mediaType
+}
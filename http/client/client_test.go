@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ragpanda/go-toolkit/biz"
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeProblem(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		problem := bizerr.ToProblemDetails(ctx, bizerr.ErrNotFound.WithMessage("order not found"), r.URL.Path)
+		w.Header().Set("Content-Type", bizerr.ProblemContentType)
+		w.WriteHeader(problem.Status)
+		_, _ = w.Write(utils.MustJsonEncodeBytes(problem))
+	}))
+	defer server.Close()
+
+	c := NewHttpClient(HttpOptionalArgs{})
+	result := c.DoJson(ctx, server.URL+"/orders/1", nil)
+	assert.Error(t, result.Error())
+	assert.Equal(t, http.StatusNotFound, result.StatusCode)
+
+	be, ok := result.DecodeProblem(ctx)
+	assert.True(t, ok)
+	assert.True(t, errors.Is(be, bizerr.ErrNotFound))
+	assert.Equal(t, "order not found", be.Message())
+}
+
+func TestDoJsonRetryClass(t *testing.T) {
+	ctx := context.Background()
+	requestTimes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestTimes++
+		if r.URL.Path == "/bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c := NewHttpClient(HttpOptionalArgs{MaxRetryTimes: 3, RetryIntervalMillSec: 1})
+
+	result := c.DoJson(ctx, server.URL+"/bad", nil)
+	assert.Equal(t, utils.RetryClassPermanent, utils.ClassifyError(result.Error()))
+	assert.Equal(t, 1, requestTimes)
+
+	requestTimes = 0
+	result = c.DoJson(ctx, server.URL+"/unavailable", nil)
+	assert.Equal(t, utils.RetryClassRetryable, utils.ClassifyError(result.Error()))
+	assert.Equal(t, 3, requestTimes)
+}
+
+func TestDoJsonRemoteError(t *testing.T) {
+	ctx := context.Background()
+	requestTimes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestTimes++
+		bizData := biz.NewBizData()
+		bizData.LogID = "remote-log-1"
+		status, body := bizerr.ToErrorBody(biz.SetBizData(ctx, bizData), bizerr.ErrNotFound.WithMessage("order not found"))
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(status)
+		_, _ = w.Write(utils.MustJsonEncodeBytes(body))
+	}))
+	defer server.Close()
+
+	c := NewHttpClient(HttpOptionalArgs{MaxRetryTimes: 3, RetryIntervalMillSec: 1})
+	result := c.DoJson(ctx, server.URL+"/orders/1", nil, func(args *HttpOptionalArgs) {
+		args.ServiceName = "order-service"
+	})
+
+	var be bizerr.BusinessError
+	assert.True(t, errors.As(result.Error(), &be))
+	assert.True(t, errors.Is(be, bizerr.ErrNotFound))
+	assert.Equal(t, "order not found", be.Message())
+	assert.Equal(t, "order-service", be.Remote().Service)
+	assert.Equal(t, "remote-log-1", be.Remote().LogID)
+	assert.Equal(t, http.StatusNotFound, be.Remote().StatusCode)
+	assert.Equal(t, 1, requestTimes)
+}
+
+func TestDoJsonContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	c := NewHttpClient(HttpOptionalArgs{})
+	result := c.DoJson(ctx, server.URL, nil)
+	assert.True(t, errors.Is(result.Error(), context.DeadlineExceeded))
+	assert.Less(t, time.Since(start), time.Second)
+}
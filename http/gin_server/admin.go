@@ -0,0 +1,100 @@
+package gin_server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/pprof"
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/utils"
+)
+
+type AdminConfig struct {
+	// Addr 管理端口监听地址，如 127.0.0.1:9090，为空时不启动管理端口
+	Addr string `yaml:"Addr" json:"Addr"`
+	// Accounts 用户名 -> 密码，非空时开启 basic auth
+	Accounts map[string]string `yaml:"Accounts" json:"Accounts"`
+	// AllowCIDRs 允许访问的来源网段，按 TCP 连接的对端地址判断，为空时不限制
+	AllowCIDRs []string `yaml:"AllowCIDRs" json:"AllowCIDRs"`
+	// MetricsPath 默认 /metrics
+	MetricsPath string `yaml:"MetricsPath" json:"MetricsPath"`
+}
+
+// initAdmin 创建管理端口的 engine，承载 pprof、metrics、健康检查与调试路由
+func (self *GinHttpServer) initAdmin() error {
+	config := self.config.Admin
+	checkers := make([]*utils.CIDRChecker, 0, len(config.AllowCIDRs))
+	for _, cidr := range config.AllowCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid admin cidr %q: %w", cidr, err)
+		}
+		checkers = append(checkers, utils.NewCIDRChecker(cidr))
+	}
+
+	self.adminEngine = gin.New()
+	self.adminEngine.Use(gin.Logger(), NewRecoveryMW(self.panicReporters...))
+	if len(checkers) != 0 {
+		self.adminEngine.Use(cidrMW(checkers))
+	}
+	if len(config.Accounts) != 0 {
+		self.adminEngine.Use(gin.BasicAuth(config.Accounts))
+	}
+
+	pprof.Register(self.adminEngine, self.config.ProfilePath)
+	healthConfig := &HealthConfig{LivenessPath: "/healthz", ReadinessPath: "/readyz"}
+	if self.config.Health != nil {
+		healthConfig = self.config.Health
+	}
+	RegisterHealthRoutes(self.adminEngine, self.health, healthConfig)
+	self.adminEngine.GET("/debug/error-codes", func(c *gin.Context) {
+		c.Header("Content-Type", "application/json; charset=utf-8")
+		if err := bizerr.DumpRegistry(c.Writer, bizerr.DumpFormatJSON); err != nil {
+			RenderError(c, err)
+		}
+	})
+	if self.metricsHandler != nil {
+		self.adminEngine.GET(config.MetricsPath, gin.WrapH(self.metricsHandler))
+	}
+
+	self.adminServer = &http.Server{
+		Addr:           config.Addr,
+		Handler:        self.adminEngine,
+		ReadTimeout:    60 * time.Second,
+		WriteTimeout:   60 * time.Second,
+		MaxHeaderBytes: 1 << 20,
+	}
+	return nil
+}
+
+// SetMetricsHandler 设置管理端口 metrics 抓取接口的 handler，如 MetricsHub.Handler()，需在 Init 前调用
+func (self *GinHttpServer) SetMetricsHandler(handler http.Handler) *GinHttpServer {
+	self.metricsHandler = handler
+	return self
+}
+
+// GetAdminEngine 获取管理端口的 engine 以注册其他调试路由，未配置管理端口时为 nil
+func (self *GinHttpServer) GetAdminEngine() *gin.Engine {
+	return self.adminEngine
+}
+
+func (self *GinHttpServer) adminEnabled() bool {
+	return self.config.Admin != nil && self.config.Admin.Addr != ""
+}
+
+// cidrMW 仅允许来自指定网段的连接，不信任 X-Forwarded-For 等请求头
+func cidrMW(checkers []*utils.CIDRChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.RemoteIP()
+		for _, checker := range checkers {
+			if checker.Check(ip) {
+				c.Next()
+				return
+			}
+		}
+		RenderError(c, bizerr.ErrForbidden.WithMessage("admin access denied"))
+		c.Abort()
+	}
+}
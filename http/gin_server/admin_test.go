@@ -0,0 +1,52 @@
+package gin_server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminServer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := NewGinHttpServer(&GinConfig{
+		EnablePprof: true,
+		Admin: &AdminConfig{
+			Addr:       "127.0.0.1:0",
+			Accounts:   map[string]string{"admin": "secret"},
+			AllowCIDRs: []string{"192.0.2.0/24"},
+		},
+	}).SetHealthRegistry(health.NewRegistry(0)).
+		SetMetricsHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("api_requests 1"))
+		})).
+		Init()
+
+	serve := func(engine *gin.Engine, path, remoteAddr string, auth bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		if auth {
+			req.SetBasicAuth("admin", "secret")
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	admin := server.GetAdminEngine()
+	assert.NotNil(t, admin)
+	for _, path := range []string{"/debug/pprof/", "/metrics", "/readyz", "/debug/error-codes"} {
+		w := serve(admin, path, "192.0.2.10:5000", true)
+		assert.Equal(t, http.StatusOK, w.Code, path)
+	}
+	assert.Equal(t, "api_requests 1", serve(admin, "/metrics", "192.0.2.10:5000", true).Body.String())
+	assert.Equal(t, http.StatusUnauthorized, serve(admin, "/metrics", "192.0.2.10:5000", false).Code)
+	assert.Equal(t, http.StatusForbidden, serve(admin, "/metrics", "10.0.0.1:5000", true).Code)
+
+	// 配置管理端口后业务端口不再暴露 pprof
+	assert.Equal(t, http.StatusNotFound, serve(server.GetEngine(), "/debug/pprof/", "192.0.2.10:5000", true).Code)
+}
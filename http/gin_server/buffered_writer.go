@@ -0,0 +1,140 @@
+package gin_server
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bufferedWriter 缓冲 handler 的响应，由中间件在 handler 结束后决定如何写出，不适用于流式响应。
+// 响应头在创建时从原始 writer 复制，handler 的修改不影响原始 writer
+type bufferedWriter struct {
+	gin.ResponseWriter
+
+	lock   sync.Mutex
+	header http.Header
+	body   bytes.Buffer
+	status int
+	// rejectWrite 持锁调用，返回非 nil 时丢弃写入
+	rejectWrite func() error
+}
+
+func newBufferedWriter(origin gin.ResponseWriter) *bufferedWriter {
+	return &bufferedWriter{ResponseWriter: origin, header: origin.Header().Clone()}
+}
+
+func (w *bufferedWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.rejected() != nil || w.status != 0 {
+		return
+	}
+	w.status = code
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if err := w.rejected(); err != nil {
+		return 0, err
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *bufferedWriter) rejected() error {
+	if w.rejectWrite == nil {
+		return nil
+	}
+	return w.rejectWrite()
+}
+
+func (w *bufferedWriter) Status() int {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.status == 0 {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.status != 0
+}
+
+// Flush 响应完成前无法提前输出
+func (w *bufferedWriter) Flush() {}
+
+func (w *bufferedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.New("hijack is not supported on a buffered response")
+}
+
+// addedHeader 返回 handler 新增或修改的响应头，base 为创建 writer 时原始 writer 的响应头
+func (w *bufferedWriter) addedHeader(base http.Header) http.Header {
+	added := http.Header{}
+	for k, v := range w.header {
+		if !equalValues(base[k], v) {
+			added[k] = append([]string(nil), v...)
+		}
+	}
+	return added
+}
+
+// flushTo 将缓冲的响应写入 origin，调用方需持有 lock 或确保 handler 已结束
+func (w *bufferedWriter) flushTo(origin gin.ResponseWriter) {
+	dst := origin.Header()
+	for k, v := range w.header {
+		dst[k] = v
+	}
+	if w.status == 0 {
+		return
+	}
+	origin.WriteHeader(w.status)
+	if w.body.Len() != 0 {
+		_, _ = origin.Write(w.body.Bytes())
+	} else {
+		origin.WriteHeaderNow()
+	}
+}
+
+func equalValues(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -6,12 +6,30 @@ type GinConfig struct {
 
 	EnableBaseMw bool `yaml:"EnableBaseMw" json:"EnableBaseMw"`
 	EnablePprof  bool `yaml:"EnablePprof" json:"EnablePprof"`
+	// EnableH2C 未开启 TLS 时支持 HTTP/2 明文连接，适用于网格内部流量
+	EnableH2C bool `yaml:"EnableH2C" json:"EnableH2C"`
 
-	ProfilePath string           `yaml:"ProfilePath" json:"ProfilePath"`
-	CORS        *CORSConfig      `yaml:"CORS" json:"CORS"`
-	RateLimit   *RateLimitConfig `yaml:"RateLimit" json:"RateLimit"`
+	ProfilePath string             `yaml:"ProfilePath" json:"ProfilePath"`
+	CORS        *CORSConfig        `yaml:"CORS" json:"CORS"`
+	RateLimit   *RateLimitConfig   `yaml:"RateLimit" json:"RateLimit"`
+	BodyLog     *BodyLogConfig     `yaml:"BodyLog" json:"BodyLog"`
+	JWTAuth     *JWTAuthConfig     `yaml:"JWTAuth" json:"JWTAuth"`
+	Signature   *SignatureConfig   `yaml:"Signature" json:"Signature"`
+	TLS         *TLSConfig         `yaml:"TLS" json:"TLS"`
+	Health      *HealthConfig      `yaml:"Health" json:"Health"`
+	Admin       *AdminConfig       `yaml:"Admin" json:"Admin"`
+	Compress    *CompressConfig    `yaml:"Compress" json:"Compress"`
+	Cache       *CacheConfig       `yaml:"Cache" json:"Cache"`
+	Idempotency *IdempotencyConfig `yaml:"Idempotency" json:"Idempotency"`
+	Timeout     *TimeoutConfig     `yaml:"Timeout" json:"Timeout"`
+
+	// ReadTimeoutSec/WriteTimeoutSec 连接级超时，默认 60，需大于 Timeout 中最长的路由超时
+	ReadTimeoutSec  int64 `yaml:"ReadTimeoutSec" json:"ReadTimeoutSec"`
+	WriteTimeoutSec int64 `yaml:"WriteTimeoutSec" json:"WriteTimeoutSec"`
 
 	GracefulExitSec int64 `yaml:"GracefulExitSec" json:"GracefulExitSec"`
+	// PreStopDelaySec 退出时 readiness 置为失败后等待负载均衡摘除流量的时间，计入 GracefulExitSec
+	PreStopDelaySec int64 `yaml:"PreStopDelaySec" json:"PreStopDelaySec"`
 }
 
 type CORSConfig struct {
@@ -0,0 +1,224 @@
+package gin_server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/ragpanda/go-toolkit/biz"
+	"github.com/ragpanda/go-toolkit/bizerr"
+)
+
+// SuccessCode 成功响应的 code
+const SuccessCode = "OK"
+
+// Response 统一的成功响应格式，与 bizerr.ErrorBody 的 code/message/log_id 字段保持一致
+type Response[T any] struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	LogID   string `json:"log_id,omitempty"`
+	Data    T      `json:"data"`
+}
+
+// Handle 将 func(ctx, Req) (Resp, error) 适配为 gin.HandlerFunc：
+// 依次从 query、header、body、path 绑定 Req（form/header/json/uri 标签，后者覆盖前者，
+// query/header/path 只绑定带对应标签的字段），
+// 全部绑定后按 binding 标签统一校验，失败时返回带 FieldViolations 的 InvalidInput；
+// ctx 继承请求的 deadline，并可读取 BizData 等 gin 上下文中的值；
+// 成功时以 Response 包装输出，失败时按 bizerr 映射 HTTP 状态码输出，并加入 c.Errors 供 errsink 等中间件收集。
+//
+//	engine.POST("/users/:id", gin_server.Handle(userService.Update))
+func Handle[Req any, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req Req
+		if err := bindRequest(c, &req); err != nil {
+			_ = c.Error(err)
+			RenderError(c, err)
+			c.Abort()
+			return
+		}
+
+		resp, err := fn(handlerContext{Context: c.Request.Context(), c: c}, req)
+		if err != nil {
+			_ = c.Error(err)
+			RenderError(c, err)
+			c.Abort()
+			return
+		}
+
+		var logID string
+		if bizData := biz.GetBizData(c); bizData != nil {
+			logID = bizData.LogID
+		}
+		c.JSON(http.StatusOK, &Response[Resp]{Code: SuccessCode, LogID: logID, Data: resp})
+	}
+}
+
+// GinContextFrom 从 Handle 传入的 ctx 中取出 gin.Context，用于 AddCacheTags 等需要 gin 上下文的场景
+func GinContextFrom(ctx context.Context) *gin.Context {
+	c, _ := ctx.Value(gin.ContextKey).(*gin.Context)
+	return c
+}
+
+// handlerContext 取消与 deadline 来自请求 ctx，取值时回退到 gin.Context
+type handlerContext struct {
+	context.Context
+	c *gin.Context
+}
+
+func (ctx handlerContext) Value(key any) any {
+	if v := ctx.Context.Value(key); v != nil {
+		return v
+	}
+	return ctx.c.Value(key)
+}
+
+func bindRequest(c *gin.Context, req any) error {
+	// query/header/path 只绑定带对应标签的字段，避免无标签字段被同名参数覆盖；
+	// body 在 query/header 之后绑定，query/header 的 default 不会覆盖 body 中的值
+	reqType := reflect.TypeOf(req)
+	query := c.Request.URL.Query()
+	if err := binding.MapFormWithTag(req, taggedValues(reqType, "form", func(key string) []string { return query[key] }), "form"); err != nil {
+		return bizerr.Wrap(err, bizerr.InvalidInput, "invalid query parameters")
+	}
+	if err := binding.MapFormWithTag(req, taggedValues(reqType, "header", c.Request.Header.Values), "header"); err != nil {
+		return bizerr.Wrap(err, bizerr.InvalidInput, "invalid request headers")
+	}
+	// body 绑定时 gin 会校验，此时字段尚未绑定完整，忽略校验错误，全部绑定后统一校验
+	if c.Request.Body != nil && c.Request.ContentLength != 0 &&
+		c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		if err := c.ShouldBindWith(req, binding.Default(c.Request.Method, c.ContentType())); err != nil &&
+			!isValidationError(err) && !errors.Is(err, io.EOF) {
+			return bizerr.Wrap(err, bizerr.InvalidInput, "invalid request body")
+		}
+	}
+	params := func(key string) []string {
+		if v, ok := c.Params.Get(key); ok {
+			return []string{v}
+		}
+		return nil
+	}
+	if err := binding.MapFormWithTag(req, taggedValues(reqType, "uri", params), "uri"); err != nil {
+		return bizerr.Wrap(err, bizerr.InvalidInput, "invalid path parameters")
+	}
+
+	if binding.Validator == nil {
+		return nil
+	}
+	err := binding.Validator.ValidateStruct(req)
+	if err == nil {
+		return nil
+	}
+	invalid := bizerr.ErrInvalidInput.WithMessage("request validation failed")
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return invalid.WithFieldViolation("", err.Error())
+	}
+	for _, fe := range fieldErrors {
+		description := fmt.Sprintf("failed on the '%s' rule", fe.Tag())
+		if fe.Param() != "" {
+			description = fmt.Sprintf("failed on the '%s=%s' rule", fe.Tag(), fe.Param())
+		}
+		invalid = invalid.WithFieldViolation(violationField(reflect.TypeOf(req), fe.StructNamespace()), description)
+	}
+	return invalid
+}
+
+// 字段名按绑定来源的标签解析，与客户端传入的名称一致
+var violationFieldTags = []string{"json", "form", "uri", "header"}
+
+// violationField 将 validator 的 StructNamespace（如 Req.Profile.Tags[0]）转换为标签名（如 profile.tags[0]），
+// 无标签的字段保留 Go 字段名，匿名嵌入的结构体不占层级
+func violationField(t reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")
+	names := make([]string, 0, len(segments))
+	for _, segment := range segments[1:] {
+		name, index := segment, ""
+		if i := strings.IndexByte(segment, '['); i >= 0 {
+			name, index = segment[:i], segment[i:]
+		}
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			names = append(names, segment)
+			t = nil
+			continue
+		}
+		field, ok := t.FieldByName(name)
+		if !ok {
+			names = append(names, segment)
+			t = nil
+			continue
+		}
+		t = field.Type
+		if index != "" {
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+				t = t.Elem()
+			}
+		}
+		tagName := fieldTagName(field)
+		if tagName == "" && field.Anonymous {
+			continue
+		}
+		if tagName == "" {
+			tagName = field.Name
+		}
+		names = append(names, tagName+index)
+	}
+	return strings.Join(names, ".")
+}
+
+func fieldTagName(field reflect.StructField) string {
+	for _, tag := range violationFieldTags {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return ""
+}
+
+// taggedValues 收集 t 中带 tag 标签的字段名在请求中的取值，供 binding.MapFormWithTag 使用
+func taggedValues(t reflect.Type, tag string, get func(key string) []string) map[string][]string {
+	values := map[string][]string{}
+	visited := map[reflect.Type]bool{}
+	var collect func(t reflect.Type)
+	collect = func(t reflect.Type) {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || visited[t] {
+			return
+		}
+		visited[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" && name != "-" {
+				if v := get(name); len(v) != 0 {
+					values[name] = v
+				}
+				continue
+			}
+			collect(field.Type)
+		}
+	}
+	collect(t)
+	return values
+}
+
+func isValidationError(err error) bool {
+	var fieldErrors validator.ValidationErrors
+	var sliceErrors binding.SliceValidationError
+	return errors.As(err, &fieldErrors) || errors.As(err, &sliceErrors)
+}
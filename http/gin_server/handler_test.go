@@ -0,0 +1,183 @@
+package gin_server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/biz"
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/bizerr/errsink"
+	"github.com/ragpanda/go-toolkit/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type updateUserReq struct {
+	ID     string `uri:"id" binding:"required"`
+	Name   string `json:"name" binding:"required,max=8"`
+	Age    int    `json:"age" binding:"gte=0"`
+	DryRun bool   `form:"dry_run"`
+	Tenant string `header:"X-Tenant-ID"`
+}
+
+type pageQuery struct {
+	Page int `form:"page" binding:"gte=1"`
+}
+
+type orderItem struct {
+	SKU   string `json:"sku" binding:"required"`
+	Count int    `binding:"gte=1"`
+}
+
+type createOrderReq struct {
+	pageQuery
+	Items []*orderItem `json:"items" binding:"required,dive"`
+}
+
+type searchReq struct {
+	Page    int `json:"page" form:"page,default=1"`
+	Keyword string
+	Tags    []string `form:"tag"`
+}
+
+type updateUserResp struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	UserID string `json:"user_id"`
+	DryRun bool   `json:"dry_run"`
+	Tenant string `json:"tenant"`
+}
+
+func TestHandle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(UserKey, "u1")
+	}, BizDataMw, ErrorRenderMW)
+	router.POST("/users/:id", Handle(func(ctx context.Context, req updateUserReq) (*updateUserResp, error) {
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+		assert.NotNil(t, GinContextFrom(ctx))
+		if req.ID == "missing" {
+			return nil, bizerr.ErrNotFound.WithMessage("user not found")
+		}
+		return &updateUserResp{
+			ID:     req.ID,
+			Name:   req.Name,
+			UserID: biz.GetBizData(ctx).UserID,
+			DryRun: req.DryRun,
+			Tenant: req.Tenant,
+		}, nil
+	}))
+
+	do := func(path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		ctx, cancel := context.WithTimeout(req.Context(), time.Second)
+		defer cancel()
+		req = req.WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "t1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("/users/42?dry_run=true", `{"name":"bob","age":3}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	resp := &Response[*updateUserResp]{}
+	assert.NoError(t, utils.Unmarshal(w.Body.Bytes(), resp))
+	assert.Equal(t, SuccessCode, resp.Code)
+	assert.NotEmpty(t, resp.LogID)
+	assert.Equal(t, &updateUserResp{ID: "42", Name: "bob", UserID: "u1", DryRun: true, Tenant: "t1"}, resp.Data)
+
+	// 校验失败返回字段明细
+	w = do("/users/42", `{"name":"a-very-long-name","age":-1}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	errBody := &bizerr.ErrorBody{}
+	assert.NoError(t, utils.Unmarshal(w.Body.Bytes(), errBody))
+	assert.Equal(t, bizerr.InvalidInput, errBody.Code)
+	assert.Equal(t, []bizerr.FieldViolation{
+		{Field: "name", Description: "failed on the 'max=8' rule"},
+		{Field: "age", Description: "failed on the 'gte=0' rule"},
+	}, errBody.Details.FieldViolations)
+
+	w = do("/users/42", `{"name":`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = do("/users/42", ``)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// 嵌套字段按标签名输出路径，匿名嵌入的结构体不占层级
+	router.POST("/orders", Handle(func(ctx context.Context, req createOrderReq) (int, error) {
+		return len(req.Items), nil
+	}))
+	w = do("/orders?page=0", `{"items":[{"sku":"a","Count":1},{"sku":"","Count":0}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	errBody = &bizerr.ErrorBody{}
+	assert.NoError(t, utils.Unmarshal(w.Body.Bytes(), errBody))
+	assert.Equal(t, []bizerr.FieldViolation{
+		{Field: "page", Description: "failed on the 'gte=1' rule"},
+		{Field: "items[1].sku", Description: "failed on the 'required' rule"},
+		{Field: "items[1].Count", Description: "failed on the 'gte=1' rule"},
+	}, errBody.Details.FieldViolations)
+
+	// query 的 default 与同名 header 不覆盖 body，无标签字段不从 query/header 绑定
+	router.POST("/search", Handle(func(ctx context.Context, req searchReq) (*searchReq, error) {
+		return &req, nil
+	}))
+	search := func(path string, body string) *searchReq {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Keyword", "from-header")
+		req.Header.Set("Page", "7")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		resp := &Response[*searchReq]{}
+		assert.NoError(t, utils.Unmarshal(w.Body.Bytes(), resp))
+		return resp.Data
+	}
+	assert.Equal(t, &searchReq{Page: 5, Keyword: "go"}, search("/search", `{"page":5,"Keyword":"go"}`))
+	assert.Equal(t, &searchReq{Page: 1, Tags: []string{"a", "b"}}, search("/search?tag=a&tag=b&Keyword=q", `{}`))
+	assert.Equal(t, &searchReq{Page: 3, Keyword: "go"}, search("/search?page=3", `{"Keyword":"go"}`))
+
+	// 业务错误映射为对应状态码
+	w = do("/users/missing", `{"name":"bob"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	errBody = &bizerr.ErrorBody{}
+	assert.NoError(t, utils.Unmarshal(w.Body.Bytes(), errBody))
+	assert.Equal(t, bizerr.NotFound, errBody.Code)
+}
+
+func TestHandleCollectErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	aggregator := errsink.NewAggregator(&errsink.AggregatorConfig{})
+	router := gin.New()
+	router.Use(BizDataMw, aggregator.CollectMW(), ErrorRenderMW)
+	router.POST("/users/:id", Handle(func(ctx context.Context, req updateUserReq) (*updateUserResp, error) {
+		return nil, bizerr.ErrNotFound.WithMessage("user not found")
+	}))
+	do := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/users/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// 业务错误与校验错误都加入 c.Errors
+	assert.Equal(t, http.StatusNotFound, do(`{"name":"bob"}`))
+	assert.Equal(t, http.StatusBadRequest, do(`{}`))
+
+	codes := map[bizerr.ErrorCode]int64{}
+	for _, report := range aggregator.Report() {
+		codes[report.Code] += report.Total
+	}
+	assert.Equal(t, map[bizerr.ErrorCode]int64{bizerr.NotFound: 1, bizerr.InvalidInput: 1}, codes)
+}
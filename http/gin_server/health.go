@@ -0,0 +1,51 @@
+package gin_server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/health"
+)
+
+type HealthConfig struct {
+	Enable bool `yaml:"Enable" json:"Enable"`
+	// LivenessPath 默认 /healthz
+	LivenessPath string `yaml:"LivenessPath" json:"LivenessPath"`
+	// ReadinessPath 默认 /readyz
+	ReadinessPath string `yaml:"ReadinessPath" json:"ReadinessPath"`
+	// CacheTTLMillSec 检查结果缓存时间，为 0 时使用 health.DefaultCacheTTL
+	CacheTTLMillSec int64 `yaml:"CacheTTLMillSec" json:"CacheTTLMillSec"`
+}
+
+// LivenessHandler 输出存活检查结果
+func LivenessHandler(registry *health.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeHealthReport(c, registry.Liveness(c))
+	}
+}
+
+// ReadinessHandler 输出汇总的就绪检查结果，失败或 draining 时返回 503
+func ReadinessHandler(registry *health.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeHealthReport(c, registry.Readiness(c))
+	}
+}
+
+// RegisterHealthRoutes 注册存活与就绪检查路由，应在鉴权等中间件之前注册
+func RegisterHealthRoutes(router gin.IRoutes, registry *health.Registry, config *HealthConfig) {
+	if config.CacheTTLMillSec != 0 {
+		registry.SetCacheTTL(time.Duration(config.CacheTTLMillSec) * time.Millisecond)
+	}
+	router.GET(config.LivenessPath, LivenessHandler(registry))
+	router.GET(config.ReadinessPath, ReadinessHandler(registry))
+}
+
+func writeHealthReport(c *gin.Context, report *health.Report) {
+	status := http.StatusOK
+	if !report.OK() {
+		status = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
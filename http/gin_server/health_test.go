@@ -0,0 +1,55 @@
+package gin_server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/health"
+	"github.com/ragpanda/go-toolkit/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	registry := health.NewRegistry(0)
+	healthy := true
+	assert.NoError(t, registry.Register(&health.Check{Name: "db", Critical: true, Check: func(ctx context.Context) error {
+		if !healthy {
+			return errors.New("db down")
+		}
+		return nil
+	}}))
+
+	router := gin.New()
+	RegisterHealthRoutes(router, registry, &HealthConfig{LivenessPath: "/healthz", ReadinessPath: "/readyz"})
+
+	get := func(path string) (int, *health.Report) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		report := &health.Report{}
+		assert.NoError(t, utils.Unmarshal(w.Body.Bytes(), report))
+		return w.Code, report
+	}
+
+	code, report := get("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusOK, report.Checks["db"].Status)
+
+	healthy = false
+	code, report = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusFail, report.Status)
+
+	registry.SetDraining(true)
+	code, report = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusDraining, report.Status)
+
+	code, _ = get("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+}
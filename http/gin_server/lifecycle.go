@@ -0,0 +1,110 @@
+package gin_server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ragpanda/go-toolkit/log"
+)
+
+// Hook 服务生命周期钩子，OnStart 按注册顺序在监听前执行，OnStop 按注册逆序在 HTTP 请求排空后执行，
+// 因此被依赖的组件（如 MongoDBPool）应先注册
+//
+//	server.AddHook(&gin_server.Hook{Name: "mongo", OnStop: pool.CloseAll})
+//	server.AddHook(&gin_server.Hook{Name: "worker", OnStop: func(ctx context.Context) error { workerPool.Close(); return nil }})
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+	// StopTimeout OnStop 的超时时间，为 0 时可使用 GracefulExitSec 剩余的全部时间
+	StopTimeout time.Duration
+}
+
+// AddHook 添加生命周期钩子，需在 Run 前调用
+func (self *GinHttpServer) AddHook(hook *Hook) *GinHttpServer {
+	self.hooks = append(self.hooks, hook)
+	return self
+}
+
+// runStartHooks 依次执行 OnStart，失败时逆序停止已启动的钩子
+func (self *GinHttpServer) runStartHooks(ctx context.Context) error {
+	for i, hook := range self.hooks {
+		if hook.OnStart == nil {
+			continue
+		}
+		if err := hook.OnStart(ctx); err != nil {
+			log.Error(ctx, "start hook %s failed %s", hook.Name, err.Error())
+			stopCtx, cancel := context.WithTimeout(context.Background(), time.Duration(self.config.GracefulExitSec)*time.Second)
+			defer cancel()
+			self.runStopHooks(stopCtx, self.hooks[:i])
+			return fmt.Errorf("start hook %s failed: %w", hook.Name, err)
+		}
+	}
+	return nil
+}
+
+// runStopHooks 逆序执行 OnStop，单个钩子超时后记录日志并继续执行下一个，不阻塞整体退出
+func (self *GinHttpServer) runStopHooks(ctx context.Context, hooks []*Hook) {
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		if hook.OnStop == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			log.Error(ctx, "stop hook %s skipped, graceful exit budget exhausted", hook.Name)
+			continue
+		}
+
+		hookCtx, cancel := ctx, context.CancelFunc(func() {})
+		if hook.StopTimeout > 0 {
+			hookCtx, cancel = context.WithTimeout(ctx, hook.StopTimeout)
+		}
+		start := time.Now()
+		done := make(chan error, 1)
+		go func() {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					done <- fmt.Errorf("panic: %v", recovered)
+				}
+			}()
+			done <- hook.OnStop(hookCtx)
+		}()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				log.Error(ctx, "stop hook %s failed %s, cost:%v", hook.Name, err.Error(), time.Since(start))
+			} else {
+				log.Info(ctx, "stop hook %s done, cost:%v", hook.Name, time.Since(start))
+			}
+		case <-hookCtx.Done():
+			log.Error(ctx, "stop hook %s exceeded deadline, cost:%v", hook.Name, time.Since(start))
+		}
+		cancel()
+	}
+}
+
+// gracefulStop 按顺序退出：readiness 置为失败、等待负载均衡摘除流量、排空 HTTP 请求、逆序执行 OnStop，
+// 全部步骤共享 GracefulExitSec 的时间预算
+func (self *GinHttpServer) gracefulStop(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(self.config.GracefulExitSec)*time.Second)
+	defer cancel()
+
+	self.health.SetDraining(true)
+	if delay := time.Duration(self.config.PreStopDelaySec) * time.Second; delay > 0 {
+		log.Info(ctx, "Server is draining, wait %v before shutdown", delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+	}
+
+	var e error
+	if err := self.Shutdown(ctx); err != nil {
+		log.Error(ctx, "Server Shutdown: %s", err.Error())
+		e = err
+	}
+	self.runStopHooks(ctx, self.hooks)
+	return e
+}
@@ -0,0 +1,107 @@
+package gin_server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycleHooks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	registry := health.NewRegistry(0)
+	server := NewGinHttpServer(&GinConfig{Addr: addr, GracefulExitSec: 2}).SetHealthRegistry(registry)
+
+	lock := sync.Mutex{}
+	var events []string
+	record := func(event string) {
+		lock.Lock()
+		defer lock.Unlock()
+		events = append(events, event)
+	}
+	newHook := func(name string) *Hook {
+		return &Hook{
+			Name: name,
+			OnStart: func(ctx context.Context) error {
+				record("start " + name)
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				assert.True(t, registry.Draining())
+				record("stop " + name)
+				return nil
+			},
+		}
+	}
+	server.AddHook(newHook("mongo")).AddHook(newHook("worker"))
+	server.AddHook(&Hook{
+		Name:        "slow",
+		StopTimeout: 50 * time.Millisecond,
+		OnStop: func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		},
+	})
+	server.Init()
+	server.GetEngine().GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- server.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + addr + "/ping")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+
+	start := time.Now()
+	cancel()
+	assert.NoError(t, <-runErr)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, []string{"start mongo", "start worker", "stop worker", "stop mongo"}, events)
+}
+
+func TestLifecycleStartHookFailed(t *testing.T) {
+	server := NewGinHttpServer(&GinConfig{GracefulExitSec: 1})
+	var stopped []string
+	server.AddHook(&Hook{
+		Name: "mongo",
+		OnStop: func(ctx context.Context) error {
+			stopped = append(stopped, "mongo")
+			return nil
+		},
+	}).AddHook(&Hook{
+		Name:    "cache",
+		OnStart: func(ctx context.Context) error { return errors.New("connect failed") },
+		OnStop: func(ctx context.Context) error {
+			stopped = append(stopped, "cache")
+			return nil
+		},
+	})
+	server.Init()
+
+	err := server.Run(context.Background())
+	assert.ErrorContains(t, err, "connect failed")
+	assert.Equal(t, []string{"mongo"}, stopped)
+}
@@ -0,0 +1,251 @@
+package gin_server
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/biz"
+	"github.com/ragpanda/go-toolkit/log"
+	"github.com/ragpanda/go-toolkit/utils"
+)
+
+type BodyLogConfig struct {
+	Enable bool `yaml:"Enable" json:"Enable"`
+	// Rules 按路径前缀开启 body 日志，未匹配任何规则的请求不记录
+	Rules []*BodyLogRule `yaml:"Rules" json:"Rules"`
+	// MaxBodyBytes body 截断长度，默认 4096
+	MaxBodyBytes int `yaml:"MaxBodyBytes" json:"MaxBodyBytes"`
+	// MaskFields 需要脱敏的 JSON/表单字段，忽略大小写，为空时使用默认列表
+	MaskFields []string `yaml:"MaskFields" json:"MaskFields"`
+	// MaskHeaders 需要脱敏的请求头，忽略大小写，为空时使用默认列表
+	MaskHeaders []string `yaml:"MaskHeaders" json:"MaskHeaders"`
+}
+
+type BodyLogRule struct {
+	// MatchPathPrefix 匹配路径前缀
+	MatchPathPrefix string `yaml:"MatchPathPrefix" json:"MatchPathPrefix"`
+	// SkipRequest 不记录请求 body
+	SkipRequest bool `yaml:"SkipRequest" json:"SkipRequest"`
+	// SkipResponse 不记录响应 body
+	SkipResponse bool `yaml:"SkipResponse" json:"SkipResponse"`
+	// MaxBodyBytes 覆盖全局截断长度
+	MaxBodyBytes int `yaml:"MaxBodyBytes" json:"MaxBodyBytes"`
+}
+
+var (
+	defaultMaskFields  = []string{"password", "passwd", "secret", "token", "access_token", "refresh_token", "authorization"}
+	defaultMaskHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+)
+
+const maskedValue = "***"
+
+type bodyLogger struct {
+	config      BodyLogConfig
+	maskFields  map[string]bool
+	maskHeaders map[string]bool
+	maskPattern *regexp.Regexp
+}
+
+// NewBodyLogMW 创建请求/响应 body 日志中间件，按路径前缀开启，截断超长 body、跳过二进制内容并对敏感字段脱敏
+func NewBodyLogMW(config *BodyLogConfig) gin.HandlerFunc {
+	return newBodyLogger(config).handle
+}
+
+func newBodyLogger(config *BodyLogConfig) *bodyLogger {
+	l := &bodyLogger{
+		config:      *config,
+		maskFields:  make(map[string]bool),
+		maskHeaders: make(map[string]bool),
+	}
+	if l.config.MaxBodyBytes <= 0 {
+		l.config.MaxBodyBytes = 4096
+	}
+	maskFields := utils.GetNonEmptySlice(l.config.MaskFields, defaultMaskFields)
+	quoted := make([]string, 0, len(maskFields))
+	for _, field := range maskFields {
+		l.maskFields[strings.ToLower(field)] = true
+		quoted = append(quoted, regexp.QuoteMeta(field))
+	}
+	for _, header := range utils.GetNonEmptySlice(l.config.MaskHeaders, defaultMaskHeaders) {
+		l.maskHeaders[http.CanonicalHeaderKey(header)] = true
+	}
+	// 截断后的 JSON 无法解析，退化为正则替换字段值，字符串之外的数字、布尔等标量同样脱敏
+	l.maskPattern = regexp.MustCompile(`(?i)("(?:` + strings.Join(quoted, "|") + `)"\s*:\s*)(?:"(?:[^"\\]|\\.)*"?|[^\s,{}\[\]"]+)`)
+	return l
+}
+
+func (l *bodyLogger) handle(c *gin.Context) {
+	rule := l.match(c.Request.URL.Path)
+	if rule == nil {
+		c.Next()
+		return
+	}
+	limit := l.config.MaxBodyBytes
+	if rule.MaxBodyBytes > 0 {
+		limit = rule.MaxBodyBytes
+	}
+
+	var reqBody string
+	if !rule.SkipRequest && c.Request.Body != nil {
+		head := make([]byte, limit+1)
+		n, _ := io.ReadFull(c.Request.Body, head)
+		head = head[:n]
+		c.Request.Body = readCloser{
+			Reader: io.MultiReader(bytes.NewReader(head), c.Request.Body),
+			Closer: c.Request.Body,
+		}
+		reqBody = l.formatBody(c.ContentType(), head, limit)
+	}
+
+	var writer *bodyLogWriter
+	if !rule.SkipResponse {
+		writer = &bodyLogWriter{ResponseWriter: c.Writer, limit: limit}
+		c.Writer = writer
+	}
+
+	c.Next()
+
+	var respBody string
+	if writer != nil {
+		c.Writer = writer.ResponseWriter
+		respBody = l.formatBody(writer.Header().Get("Content-Type"), writer.body.Bytes(), limit)
+	}
+
+	var logID string
+	if bizData := biz.GetBizData(c); bizData != nil {
+		logID = bizData.LogID
+	}
+	log.Info(c, "[body] api:%s %s %d headers:%s req:%s resp:%s, log_id:%s",
+		c.Request.Method, c.Request.URL.Path, c.Writer.Status(),
+		utils.Display(l.maskHeader(c.Request.Header)), reqBody, respBody, logID,
+	)
+}
+
+func (l *bodyLogger) match(path string) *BodyLogRule {
+	for _, rule := range l.config.Rules {
+		if strings.HasPrefix(path, rule.MatchPathPrefix) {
+			return rule
+		}
+	}
+	return nil
+}
+
+func (l *bodyLogger) formatBody(contentType string, body []byte, limit int) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if !isTextContent(contentType) {
+		return "[binary content]"
+	}
+
+	truncated := len(body) > limit
+	if truncated {
+		body = body[:limit]
+	}
+	masked := l.maskBody(contentType, body, truncated)
+	if truncated {
+		masked += "...(truncated)"
+	}
+	return masked
+}
+
+func (l *bodyLogger) maskBody(contentType string, body []byte, truncated bool) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded" && !truncated:
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return string(body)
+		}
+		for k := range values {
+			if l.maskFields[strings.ToLower(k)] {
+				values.Set(k, maskedValue)
+			}
+		}
+		return values.Encode()
+	case strings.HasSuffix(mediaType, "json") && !truncated:
+		var data any
+		if err := utils.Unmarshal(body, &data); err == nil {
+			return utils.MustJsonEncodeString(l.maskJson(data))
+		}
+	}
+	return l.maskPattern.ReplaceAllString(string(body), `${1}"`+maskedValue+`"`)
+}
+
+func (l *bodyLogger) maskJson(data any) any {
+	switch v := data.(type) {
+	case map[string]any:
+		for k, item := range v {
+			if l.maskFields[strings.ToLower(k)] {
+				v[k] = maskedValue
+			} else {
+				v[k] = l.maskJson(item)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = l.maskJson(item)
+		}
+	}
+	return data
+}
+
+func (l *bodyLogger) maskHeader(header http.Header) map[string]string {
+	result := make(map[string]string, len(header))
+	for k := range header {
+		if l.maskHeaders[k] {
+			result[k] = maskedValue
+		} else {
+			result[k] = header.Get(k)
+		}
+	}
+	return result
+}
+
+func isTextContent(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "json") ||
+		strings.HasSuffix(mediaType, "xml") ||
+		mediaType == "application/x-www-form-urlencoded"
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyLogWriter 在写出响应的同时保留前 limit+1 字节，多出的 1 字节用于判断是否截断
+type bodyLogWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	limit int
+}
+
+func (w *bodyLogWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyLogWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyLogWriter) capture(data []byte) {
+	if remain := w.limit + 1 - w.body.Len(); remain > 0 {
+		if len(data) > remain {
+			data = data[:remain]
+		}
+		w.body.Write(data)
+	}
+}
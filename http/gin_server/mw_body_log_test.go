@@ -0,0 +1,80 @@
+package gin_server
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/log"
+	logrus_support "github.com/ragpanda/go-toolkit/log/logrus-support"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLogMW(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	l := NewBodyLogMW(&BodyLogConfig{
+		Enable:       true,
+		MaxBodyBytes: 64,
+		Rules:        []*BodyLogRule{{MatchPathPrefix: "/api/"}},
+	})
+
+	output := &bytes.Buffer{}
+	logger := logrus_support.NewLogrusLogger(context.Background(), &logrus_support.LogrusConfig{
+		Output:       output,
+		DisableColor: true,
+	})
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(log.LoggerCtxKey, logger)
+	}, BizDataMw, l)
+	router.POST("/api/echo", func(c *gin.Context) {
+		data, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusOK, "application/json", data)
+	})
+
+	body := `{"user":"tom","password":"123456","profile":{"token":"abc"}}`
+	req := httptest.NewRequest("POST", "/api/echo", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, body, w.Body.String())
+	logged := output.String()
+	assert.Contains(t, logged, "[body] api:POST /api/echo 200")
+	assert.Contains(t, logged, `req:{"password":"***","profile":{"token":"***"},"user":"tom"}`)
+	assert.Contains(t, logged, `resp:{"password":"***","profile":{"token":"***"},"user":"tom"}`)
+	assert.NotContains(t, logged, "123456")
+	assert.NotContains(t, logged, "Bearer secret")
+
+	// 超出 MaxBodyBytes 时截断，数字类型的敏感字段同样脱敏
+	output.Reset()
+	longBody := `{"user":"tom","password":123456,"note":"` + strings.Repeat("x", 64) + `"}`
+	req = httptest.NewRequest("POST", "/api/echo", strings.NewReader(longBody))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, longBody, w.Body.String())
+	logged = output.String()
+	assert.Contains(t, logged, `req:{"user":"tom","password":"***","note":"`)
+	assert.Contains(t, logged, "...(truncated)")
+	assert.NotContains(t, logged, "123456")
+
+	bl := newBodyLogger(&BodyLogConfig{MaskFields: []string{"password", "token"}})
+	assert.Equal(t, `{"password":"***","profile":{"token":"***"},"user":"tom"}`,
+		bl.formatBody("application/json", []byte(body), 1024))
+	assert.Equal(t, `{"user":"tom","password":"***"...(truncated)`,
+		bl.formatBody("application/json", []byte(body), 30))
+	assert.Equal(t, `{"user":"tom","password":"***","pin"...(truncated)`,
+		bl.formatBody("application/json", []byte(`{"user":"tom","password":123456,"pin":12}`), 37))
+	assert.Equal(t, `{"token":"***","token":"***"...(truncated)`,
+		bl.formatBody("application/json", []byte(`{"token":true,"token":null,"x":1}`), 26))
+	assert.Equal(t, "password=%2A%2A%2A&user=tom",
+		bl.formatBody("application/x-www-form-urlencoded", []byte("user=tom&password=123"), 1024))
+	assert.Equal(t, "[binary content]", bl.formatBody("image/png", []byte{0x89, 0x50}, 1024))
+}
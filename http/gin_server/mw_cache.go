@@ -0,0 +1,331 @@
+package gin_server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/biz"
+	"github.com/ragpanda/go-toolkit/log"
+	"github.com/ragpanda/go-toolkit/metrics"
+	"github.com/ragpanda/go-toolkit/utils"
+	"github.com/ragpanda/go-toolkit/utils/httpcache"
+)
+
+const (
+	// CacheTagsKey gin 上下文中本次响应附加的缓存标签，通过 AddCacheTags 设置
+	CacheTagsKey = "cache_tags"
+	// CacheStatusHeader 响应是否命中缓存，取值 HIT/MISS
+	CacheStatusHeader = "X-Cache"
+)
+
+type CacheConfig struct {
+	Enable bool `yaml:"Enable" json:"Enable"`
+	// Rules 按路径前缀开启缓存，按顺序匹配第一条，未匹配的请求不缓存
+	Rules []*CacheRule `yaml:"Rules" json:"Rules"`
+	// MaxEntries 内存缓存条目上限，默认 10000
+	MaxEntries int `yaml:"MaxEntries" json:"MaxEntries"`
+	// MaxBytes 内存缓存总大小上限，默认 64MB
+	MaxBytes int64 `yaml:"MaxBytes" json:"MaxBytes"`
+	// MaxBodyBytes 单个响应 body 上限，超出时不缓存，默认 1MB
+	MaxBodyBytes int `yaml:"MaxBodyBytes" json:"MaxBodyBytes"`
+}
+
+type CacheRule struct {
+	// MatchPathPrefix 匹配路径前缀，通过 ResponseCache.Cache 用于单个路由时忽略
+	MatchPathPrefix string `yaml:"MatchPathPrefix" json:"MatchPathPrefix"`
+	// TTLSec 缓存时长
+	TTLSec int64 `yaml:"TTLSec" json:"TTLSec"`
+	// VaryHeaders 参与缓存 key 的请求头
+	VaryHeaders []string `yaml:"VaryHeaders" json:"VaryHeaders"`
+	// VaryBizFields 参与缓存 key 的 BizData 字段：UserID、Locale、FromIP，其余按 BizData.Custom 的 key 取值
+	VaryBizFields []string `yaml:"VaryBizFields" json:"VaryBizFields"`
+	// Tags 缓存标签，用于 ResponseCache.Invalidate 批量失效
+	Tags []string `yaml:"Tags" json:"Tags"`
+	// Private 为 true 时输出 Cache-Control: private，禁止共享缓存（CDN、代理）保存，
+	// 携带 Authorization 或已鉴权的请求总是按用户隔离缓存并输出 private
+	Private bool `yaml:"Private" json:"Private"`
+}
+
+// ResponseCache 缓存 GET 请求的 200 响应，生成 ETag 并对 If-None-Match 返回 304
+type ResponseCache struct {
+	config       CacheConfig
+	store        httpcache.Store
+	maxBodyBytes int
+}
+
+// NewResponseCache config 可为 nil，store 为 nil 时使用按 MaxEntries/MaxBytes 限制大小的进程内 LRU
+func NewResponseCache(config *CacheConfig, store httpcache.Store) *ResponseCache {
+	self := &ResponseCache{}
+	if config != nil {
+		self.config = *config
+	}
+	if store == nil {
+		maxEntries := self.config.MaxEntries
+		if maxEntries <= 0 {
+			maxEntries = 10000
+		}
+		maxBytes := self.config.MaxBytes
+		if maxBytes <= 0 {
+			maxBytes = 64 << 20
+		}
+		store = httpcache.NewMemoryStore(maxEntries, maxBytes)
+	}
+	self.store = store
+	self.maxBodyBytes = self.config.MaxBodyBytes
+	if self.maxBodyBytes <= 0 {
+		self.maxBodyBytes = 1 << 20
+	}
+	return self
+}
+
+// MW 按 CacheConfig.Rules 的路径前缀缓存
+func (self *ResponseCache) MW() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, rule := range self.config.Rules {
+			if strings.HasPrefix(c.Request.URL.Path, rule.MatchPathPrefix) {
+				self.handle(c, rule)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// Cache 为单个路由或路由组开启缓存
+//
+//	engine.GET("/articles/:id", cache.Cache(&gin_server.CacheRule{TTLSec: 60, Tags: []string{"articles"}}), handler)
+func (self *ResponseCache) Cache(rule *CacheRule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		self.handle(c, rule)
+	}
+}
+
+// Invalidate 删除携带任一标签的缓存
+func (self *ResponseCache) Invalidate(ctx context.Context, tags ...string) error {
+	return self.store.InvalidateTags(ctx, tags...)
+}
+
+func (self *ResponseCache) GetStore() httpcache.Store {
+	return self.store
+}
+
+// AddCacheTags 在 handler 中为本次响应附加缓存标签，如 "article:1"，便于数据变更时精确失效
+func AddCacheTags(c *gin.Context, tags ...string) {
+	c.Set(CacheTagsKey, append(c.GetStringSlice(CacheTagsKey), tags...))
+}
+
+func (self *ResponseCache) handle(c *gin.Context, rule *CacheRule) {
+	reqCacheControl := c.GetHeader("Cache-Control")
+	if c.Request.Method != http.MethodGet || rule.TTLSec <= 0 || strings.Contains(reqCacheControl, "no-store") {
+		c.Next()
+		return
+	}
+
+	// 前置中间件（如 CORS 的 Vary: Origin）声明的 Vary 与规则的 VaryHeaders 一同参与缓存 key
+	origin := c.Writer
+	varyHeaders := mergeVary(rule.VaryHeaders, origin.Header().Values("Vary"))
+	user := cacheUser(c)
+	private := rule.Private || len(rule.VaryBizFields) != 0 || user != ""
+	key := self.key(c, rule, varyHeaders, user)
+	path := c.FullPath()
+	if path == "" {
+		path = unmatchedPath
+	}
+
+	// no-cache 要求跳过缓存重新生成，结果仍写入缓存
+	if !strings.Contains(reqCacheControl, "no-cache") {
+		entry, err := self.store.Get(c, key)
+		if err != nil {
+			log.Warn(c, "get response cache %s failed: %v", key, err)
+		}
+		if entry != nil {
+			metrics.RecordAPICache(metrics.APICache{Path: path, Result: "hit"})
+			writeCacheEntry(c, entry, "HIT", varyHeaders, private)
+			c.Abort()
+			return
+		}
+	}
+	metrics.RecordAPICache(metrics.APICache{Path: path, Result: "miss"})
+
+	baseHeader := origin.Header().Clone()
+	writer := newBufferedWriter(origin)
+	c.Writer = writer
+	defer func() {
+		c.Writer = origin
+	}()
+
+	c.Next()
+
+	// 只缓存成功写出的 200 响应，未写出的错误交由 ErrorRenderMW 输出；
+	// 条目只保存 handler 写入的响应头，前置中间件的响应头每次请求重新生成
+	body := writer.body.Bytes()
+	header := writer.addedHeader(baseHeader)
+	if writer.status != http.StatusOK || len(c.Errors) != 0 || len(body) > self.maxBodyBytes ||
+		header.Get("Set-Cookie") != "" || strings.Contains(writer.header.Get("Cache-Control"), "no-store") ||
+		!coversVary(varyHeaders, header.Values("Vary")) {
+		writer.flushTo(origin)
+		return
+	}
+	header.Del("Content-Length")
+	header.Del("Date")
+	header.Del("Vary")
+	etag := header.Get("ETag")
+	if etag == "" {
+		etag = generateETag(body)
+	}
+	entry := &httpcache.Entry{
+		StatusCode: writer.status,
+		Header:     header,
+		Body:       append([]byte(nil), body...),
+		ETag:       etag,
+		Tags:       append(append([]string(nil), rule.Tags...), c.GetStringSlice(CacheTagsKey)...),
+		ExpireAt:   time.Now().Add(time.Duration(rule.TTLSec) * time.Second),
+	}
+	if err := self.store.Set(c, key, entry); err != nil {
+		log.Warn(c, "set response cache %s failed: %v", key, err)
+	}
+
+	c.Writer = origin
+	writeCacheEntry(c, entry, "MISS", varyHeaders, private)
+}
+
+func writeCacheEntry(c *gin.Context, entry *httpcache.Entry, cacheStatus string, varyHeaders []string, private bool) {
+	header := c.Writer.Header()
+	for k, v := range entry.Header {
+		header[k] = append([]string(nil), v...)
+	}
+	header.Set("ETag", entry.ETag)
+	header.Set(CacheStatusHeader, cacheStatus)
+	if len(varyHeaders) != 0 {
+		header.Set("Vary", strings.Join(varyHeaders, ", "))
+	}
+	if header.Get("Cache-Control") == "" {
+		maxAge := int64((time.Until(entry.ExpireAt) + time.Second - 1) / time.Second)
+		if maxAge < 0 {
+			maxAge = 0
+		}
+		scope := "public"
+		if private {
+			scope = "private"
+		}
+		header.Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", scope, maxAge))
+	}
+
+	if etagMatch(c.GetHeader("If-None-Match"), entry.ETag) {
+		header.Del("Content-Type")
+		header.Del("Content-Length")
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	header.Set("Content-Length", strconv.Itoa(len(entry.Body)))
+	c.Status(entry.StatusCode)
+	if len(entry.Body) == 0 {
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	_, _ = c.Writer.Write(entry.Body)
+}
+
+// key 由路径、排序后的 query、Vary 请求头、VaryBizFields 与请求用户组成
+func (self *ResponseCache) key(c *gin.Context, rule *CacheRule, varyHeaders []string, user string) string {
+	var buf strings.Builder
+	buf.WriteString(c.Request.URL.Path)
+	buf.WriteString("?")
+	buf.WriteString(c.Request.URL.Query().Encode())
+	for _, h := range varyHeaders {
+		buf.WriteString("\nh:" + h + "=" + c.GetHeader(h))
+	}
+	bizData := biz.GetBizData(c)
+	for _, field := range rule.VaryBizFields {
+		var value string
+		if bizData != nil {
+			switch field {
+			case "UserID":
+				value = bizData.UserID
+			case "Locale":
+				value = bizData.Locale
+			case "FromIP":
+				value = bizData.FromIP
+			default:
+				if v := bizData.GetKey(field); v != nil {
+					value = fmt.Sprint(v)
+				}
+			}
+		}
+		buf.WriteString("\nb:" + field + "=" + value)
+	}
+	if user != "" {
+		buf.WriteString("\nu:" + user)
+	}
+	return buf.String()
+}
+
+// cacheUser 已鉴权或携带 Authorization 的请求按用户隔离缓存，返回空表示匿名请求
+func cacheUser(c *gin.Context) string {
+	if bizData := biz.GetBizData(c); bizData != nil && bizData.UserID != "" {
+		return "id:" + bizData.UserID
+	}
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		sum := sha256.Sum256([]byte(auth))
+		return "auth:" + hex.EncodeToString(sum[:16])
+	}
+	return ""
+}
+
+// mergeVary 合并 Vary 请求头并规范化，忽略重复项
+func mergeVary(headers []string, values []string) []string {
+	var result []string
+	add := func(h string) {
+		h = http.CanonicalHeaderKey(strings.TrimSpace(h))
+		if h != "" && !utils.InSlice(result, h) {
+			result = append(result, h)
+		}
+	}
+	for _, h := range headers {
+		add(h)
+	}
+	for _, value := range values {
+		for _, h := range strings.Split(value, ",") {
+			add(h)
+		}
+	}
+	return result
+}
+
+// coversVary handler 新增的 Vary 请求头必须已参与缓存 key，否则不缓存
+func coversVary(varyHeaders []string, values []string) bool {
+	for _, h := range mergeVary(nil, values) {
+		if h == "*" || !utils.InSlice(varyHeaders, h) {
+			return false
+		}
+	}
+	return true
+}
+
+func generateETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatch 按弱比较判断 If-None-Match 是否命中
+func etagMatch(ifNoneMatch string, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
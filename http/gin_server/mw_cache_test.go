@@ -0,0 +1,189 @@
+package gin_server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	cache := NewResponseCache(&CacheConfig{
+		Enable: true,
+		Rules: []*CacheRule{
+			{MatchPathPrefix: "/articles", TTLSec: 60, VaryHeaders: []string{"Accept-Language"}, Tags: []string{"articles"}},
+		},
+	}, nil)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(UserKey, c.GetHeader("X-User"))
+	}, BizDataMw, ErrorRenderMW, cache.MW())
+	router.GET("/articles/:id", func(c *gin.Context) {
+		calls++
+		AddCacheTags(c, "article:"+c.Param("id"))
+		if c.Param("id") == "missing" {
+			_ = c.Error(bizerr.ErrNotFound)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "lang": c.GetHeader("Accept-Language"), "calls": calls})
+	})
+	router.GET("/me", cache.Cache(&CacheRule{TTLSec: 30, VaryBizFields: []string{"UserID"}}), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"user": c.GetString(UserKey)})
+	})
+
+	do := func(path string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("/articles/1?b=2&a=1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "MISS", w.Header().Get(CacheStatusHeader))
+	assert.Equal(t, "public, max-age=60", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	body := w.Body.String()
+
+	// query 顺序不影响缓存 key
+	w = do("/articles/1?a=1&b=2", nil)
+	assert.Equal(t, "HIT", w.Header().Get(CacheStatusHeader))
+	assert.Equal(t, body, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, 1, calls)
+
+	w = do("/articles/1?a=1&b=2", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	// VaryHeaders 参与缓存 key
+	w = do("/articles/1?a=1&b=2", map[string]string{"Accept-Language": "zh"})
+	assert.Equal(t, "MISS", w.Header().Get(CacheStatusHeader))
+	assert.Equal(t, 2, calls)
+
+	// 按标签失效
+	do("/articles/2", nil)
+	assert.NoError(t, cache.Invalidate(context.Background(), "article:1"))
+	assert.Equal(t, "MISS", do("/articles/1?a=1&b=2", nil).Header().Get(CacheStatusHeader))
+	assert.Equal(t, "HIT", do("/articles/2", nil).Header().Get(CacheStatusHeader))
+	assert.NoError(t, cache.Invalidate(context.Background(), "articles"))
+	assert.Equal(t, "MISS", do("/articles/2", nil).Header().Get(CacheStatusHeader))
+
+	// 错误响应不缓存
+	calls = 0
+	assert.Equal(t, http.StatusNotFound, do("/articles/missing", nil).Code)
+	assert.Equal(t, http.StatusNotFound, do("/articles/missing", nil).Code)
+	assert.Equal(t, 2, calls)
+
+	// 按用户隔离的路由缓存
+	w = do("/me", map[string]string{"X-User": "u1"})
+	assert.Equal(t, "private, max-age=30", w.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"user":"u1"}`, w.Body.String())
+	w = do("/me", map[string]string{"X-User": "u2"})
+	assert.JSONEq(t, `{"user":"u2"}`, w.Body.String())
+	w = do("/me", map[string]string{"X-User": "u1"})
+	assert.Equal(t, "HIT", w.Header().Get(CacheStatusHeader))
+	assert.JSONEq(t, `{"user":"u1"}`, w.Body.String())
+	assert.Equal(t, 4, calls)
+
+	// no-cache 跳过缓存
+	do("/me", map[string]string{"X-User": "u1", "Cache-Control": "no-cache"})
+	assert.Equal(t, 5, calls)
+}
+
+func TestResponseCacheHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	requests := 0
+	cache := NewResponseCache(&CacheConfig{
+		Enable: true,
+		Rules:  []*CacheRule{{MatchPathPrefix: "/", TTLSec: 60}},
+	}, nil)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		// 模拟 CORS 等前置中间件按请求写入的响应头
+		requests++
+		c.Header("X-Request-Seq", strconv.Itoa(requests))
+		c.Header("Vary", "Origin")
+		if origin := c.GetHeader("Origin"); origin != "" {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		c.Set(UserKey, c.GetHeader("X-User"))
+	}, BizDataMw, ErrorRenderMW, cache.MW())
+	router.GET("/doc", func(c *gin.Context) {
+		calls++
+		c.Header("X-Doc-Version", "v1")
+		c.String(http.StatusOK, "doc for %s %s", c.GetHeader("Origin"), c.GetHeader("X-User"))
+	})
+	router.GET("/by-agent", func(c *gin.Context) {
+		calls++
+		c.Header("Vary", "User-Agent")
+		c.String(http.StatusOK, "agent")
+	})
+
+	do := func(path string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("/doc", map[string]string{"Origin": "https://a.com"})
+	assert.Equal(t, "MISS", w.Header().Get(CacheStatusHeader))
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+
+	// 命中时前置中间件的响应头按本次请求生成，不被缓存条目覆盖
+	w = do("/doc", map[string]string{"Origin": "https://a.com"})
+	assert.Equal(t, "HIT", w.Header().Get(CacheStatusHeader))
+	assert.Equal(t, "2", w.Header().Get("X-Request-Seq"))
+	assert.Equal(t, "v1", w.Header().Get("X-Doc-Version"))
+	assert.Equal(t, "https://a.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, 1, calls)
+
+	// Vary 中的 Origin 参与缓存 key
+	w = do("/doc", map[string]string{"Origin": "https://b.com"})
+	assert.Equal(t, "MISS", w.Header().Get(CacheStatusHeader))
+	assert.Equal(t, "https://b.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "doc for https://b.com ", w.Body.String())
+	assert.Equal(t, 2, calls)
+
+	// 携带 Authorization 或已鉴权的请求按用户隔离并输出 private
+	w = do("/doc", map[string]string{"Authorization": "Bearer t1"})
+	assert.Equal(t, "MISS", w.Header().Get(CacheStatusHeader))
+	assert.Equal(t, "private, max-age=60", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "HIT", do("/doc", map[string]string{"Authorization": "Bearer t1"}).Header().Get(CacheStatusHeader))
+	assert.Equal(t, "MISS", do("/doc", map[string]string{"Authorization": "Bearer t2"}).Header().Get(CacheStatusHeader))
+	w = do("/doc", map[string]string{"X-User": "u1"})
+	assert.Equal(t, "private, max-age=60", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "doc for  u1", w.Body.String())
+	w = do("/doc", nil)
+	assert.Equal(t, "MISS", w.Header().Get(CacheStatusHeader))
+	assert.Equal(t, "public, max-age=60", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "doc for  ", w.Body.String())
+
+	// handler 声明了未参与 key 的 Vary 时不缓存
+	calls = 0
+	do("/by-agent", nil)
+	w = do("/by-agent", nil)
+	assert.Empty(t, w.Header().Get(CacheStatusHeader))
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,357 @@
+package gin_server
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/log"
+)
+
+type CompressConfig struct {
+	Enable bool `yaml:"Enable" json:"Enable"`
+	// Encodings 服务端支持的编码，按偏好排序，客户端权重相同时取靠前的，默认 br、gzip、deflate，未注册的编码忽略
+	Encodings []string `yaml:"Encodings" json:"Encodings"`
+	// Level 压缩级别，为 0 时使用各编码的默认级别
+	Level int `yaml:"Level" json:"Level"`
+	// MinSizeBytes 响应小于该值时不压缩，默认 1024
+	MinSizeBytes int `yaml:"MinSizeBytes" json:"MinSizeBytes"`
+	// ContentTypes 可压缩的 Content-Type 前缀，为空时使用默认列表，+json/+xml 后缀始终可压缩
+	ContentTypes []string `yaml:"ContentTypes" json:"ContentTypes"`
+	// SkipPathPrefixes 不压缩的路径前缀
+	SkipPathPrefixes []string `yaml:"SkipPathPrefixes" json:"SkipPathPrefixes"`
+
+	// DecompressRequest 透明解压 Content-Encoding: gzip 的请求 body
+	DecompressRequest bool `yaml:"DecompressRequest" json:"DecompressRequest"`
+	// MaxDecompressedBytes 解压后 body 上限，超出时读取报错，默认 10MB
+	MaxDecompressedBytes int64 `yaml:"MaxDecompressedBytes" json:"MaxDecompressedBytes"`
+}
+
+// Encoder 压缩编码器，实现需支持 Reset 以便复用
+type Encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// EncoderFactory 创建编码器，level 为 0 时使用默认级别
+type EncoderFactory func(w io.Writer, level int) (Encoder, error)
+
+var (
+	defaultCompressEncodings    = []string{"br", "gzip", "deflate"}
+	defaultCompressContentTypes = []string{
+		"text/", "application/json", "application/javascript", "application/xml",
+		"application/x-www-form-urlencoded", "image/svg+xml",
+	}
+
+	encoderLock      sync.RWMutex
+	encoderFactories = map[string]EncoderFactory{
+		"br": func(w io.Writer, level int) (Encoder, error) {
+			if level == 0 {
+				level = brotli.DefaultCompression
+			}
+			return brotli.NewWriterLevel(w, level), nil
+		},
+		"gzip": func(w io.Writer, level int) (Encoder, error) {
+			if level == 0 {
+				level = gzip.DefaultCompression
+			}
+			return gzip.NewWriterLevel(w, level)
+		},
+		"deflate": func(w io.Writer, level int) (Encoder, error) {
+			if level == 0 {
+				level = flate.DefaultCompression
+			}
+			return flate.NewWriter(w, level)
+		},
+	}
+)
+
+// RegisterEncoder 注册或替换压缩编码，需在创建中间件前调用，如注册 zstd：
+//
+//	gin_server.RegisterEncoder("zstd", func(w io.Writer, level int) (gin_server.Encoder, error) {
+//		return zstd.NewWriter(w)
+//	})
+func RegisterEncoder(name string, factory EncoderFactory) {
+	encoderLock.Lock()
+	defer encoderLock.Unlock()
+	encoderFactories[name] = factory
+}
+
+type compressor struct {
+	config       CompressConfig
+	encodings    []string
+	pools        map[string]*sync.Pool
+	contentTypes []string
+	minSize      int
+	maxDecoded   int64
+}
+
+// NewCompressMW 创建响应压缩中间件：按 Accept-Encoding 协商编码，仅压缩超过 MinSizeBytes 且类型可压缩的响应，
+// 编码器通过 sync.Pool 复用；Flush 时立即压缩已缓冲的数据，适用于 SSE 等流式响应。
+// 开启 DecompressRequest 时透明解压 gzip 请求 body
+func NewCompressMW(config *CompressConfig) gin.HandlerFunc {
+	return newCompressor(config).handle
+}
+
+func newCompressor(config *CompressConfig) *compressor {
+	self := &compressor{
+		config:       *config,
+		pools:        make(map[string]*sync.Pool),
+		contentTypes: config.ContentTypes,
+		minSize:      config.MinSizeBytes,
+		maxDecoded:   config.MaxDecompressedBytes,
+	}
+	if len(self.contentTypes) == 0 {
+		self.contentTypes = defaultCompressContentTypes
+	}
+	if self.minSize <= 0 {
+		self.minSize = 1024
+	}
+	if self.maxDecoded <= 0 {
+		self.maxDecoded = 10 << 20
+	}
+	encodings := config.Encodings
+	if len(encodings) == 0 {
+		encodings = defaultCompressEncodings
+	}
+
+	encoderLock.RLock()
+	defer encoderLock.RUnlock()
+	for _, name := range encodings {
+		factory, ok := encoderFactories[name]
+		if !ok {
+			continue
+		}
+		// 提前创建一次以校验压缩级别
+		if _, err := factory(io.Discard, config.Level); err != nil {
+			log.Warn(context.Background(), "skip encoding %s: %v", name, err)
+			continue
+		}
+		level := config.Level
+		self.encodings = append(self.encodings, name)
+		self.pools[name] = &sync.Pool{New: func() interface{} {
+			encoder, _ := factory(io.Discard, level)
+			return encoder
+		}}
+	}
+	return self
+}
+
+func (self *compressor) handle(c *gin.Context) {
+	if self.config.DecompressRequest && !self.decompressRequest(c) {
+		return
+	}
+	if hasAnyPrefix(c.Request.URL.Path, self.config.SkipPathPrefixes) || c.Request.Method == http.MethodHead {
+		c.Next()
+		return
+	}
+	encoding := self.negotiate(c.GetHeader("Accept-Encoding"))
+	if encoding == "" {
+		c.Next()
+		return
+	}
+
+	writer := &compressWriter{ResponseWriter: c.Writer, compressor: self, encoding: encoding}
+	c.Writer = writer
+	defer func() {
+		writer.close(c)
+		c.Writer = writer.ResponseWriter
+	}()
+	c.Next()
+}
+
+// decompressRequest 返回 false 时请求已被拒绝
+func (self *compressor) decompressRequest(c *gin.Context) bool {
+	if c.Request.Body == nil || !strings.EqualFold(c.GetHeader("Content-Encoding"), "gzip") {
+		return true
+	}
+	reader, err := gzip.NewReader(c.Request.Body)
+	if err != nil {
+		RenderError(c, bizerr.Wrap(err, bizerr.InvalidInput, "invalid gzip request body"))
+		c.Abort()
+		return false
+	}
+	c.Request.Body = readCloser{
+		Reader: http.MaxBytesReader(c.Writer, reader, self.maxDecoded),
+		Closer: c.Request.Body,
+	}
+	c.Request.Header.Del("Content-Encoding")
+	c.Request.Header.Del("Content-Length")
+	c.Request.ContentLength = -1
+	return true
+}
+
+// negotiate 按 Accept-Encoding 的权重选择编码，权重相同时按服务端偏好，q=0 表示拒绝
+func (self *compressor) negotiate(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	weights := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			if parsed, err := strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64); err == nil {
+				q = parsed
+			}
+		}
+		if name == "*" {
+			wildcard = q
+		} else {
+			weights[name] = q
+		}
+	}
+
+	var best string
+	var bestQ float64
+	for _, name := range self.encodings {
+		q, ok := weights[name]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+func (self *compressor) compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+	return hasAnyPrefix(mediaType, self.contentTypes)
+}
+
+// compressWriter 缓冲响应直到达到 MinSizeBytes、Flush 或请求结束，再决定是否压缩
+type compressWriter struct {
+	gin.ResponseWriter
+
+	compressor *compressor
+	encoding   string
+	encoder    Encoder
+	buf        []byte
+	decided    bool
+	wrote      bool
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	w.wrote = true
+	if w.decided {
+		if w.encoder != nil {
+			return w.encoder.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+	w.buf = append(w.buf, data...)
+	if len(w.buf) >= w.compressor.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow 显式提交响应头时不再压缩
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *compressWriter) Written() bool {
+	return w.wrote || w.ResponseWriter.Written()
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(true)
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide 确定是否压缩并写出已缓冲的数据，sizeOK 为 false 时不压缩
+func (w *compressWriter) decide(sizeOK bool) error {
+	w.decided = true
+	header := w.ResponseWriter.Header()
+	if sizeOK && w.shouldCompress(header) {
+		encoder := w.compressor.pools[w.encoding].Get().(Encoder)
+		encoder.Reset(w.ResponseWriter)
+		w.encoder = encoder
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.encoder != nil {
+		_, err := w.encoder.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+func (w *compressWriter) shouldCompress(header http.Header) bool {
+	status := w.ResponseWriter.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified ||
+		status == http.StatusPartialContent {
+		return false
+	}
+	if header.Get("Content-Encoding") != "" || strings.Contains(header.Get("Cache-Control"), "no-transform") {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(w.buf)
+		header.Set("Content-Type", contentType)
+	}
+	header.Add("Vary", "Accept-Encoding")
+	return w.compressor.compressible(contentType)
+}
+
+// close 在请求结束时写出剩余缓冲并归还编码器
+func (w *compressWriter) close(ctx context.Context) {
+	if !w.decided && len(w.buf) != 0 {
+		_ = w.decide(false)
+	}
+	if w.encoder == nil {
+		return
+	}
+	if err := w.encoder.Close(); err != nil {
+		log.Warn(ctx, "close %s encoder failed: %v", w.encoding, err)
+	}
+	w.encoder.Reset(io.Discard)
+	w.compressor.pools[w.encoding].Put(w.encoder)
+	w.encoder = nil
+}
@@ -0,0 +1,138 @@
+package gin_server
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressMW(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	large := strings.Repeat("hello world ", 200)
+	router := gin.New()
+	router.Use(BizDataMw, ErrorRenderMW, NewCompressMW(&CompressConfig{
+		Enable:               true,
+		MinSizeBytes:         256,
+		DecompressRequest:    true,
+		MaxDecompressedBytes: 1024,
+	}))
+	router.GET("/large", func(c *gin.Context) {
+		c.Header("ETag", `"v1"`)
+		c.String(http.StatusOK, large)
+	})
+	router.GET("/small", func(c *gin.Context) {
+		c.String(http.StatusOK, "small")
+	})
+	router.GET("/binary", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/png", []byte(large))
+	})
+	router.GET("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		_, _ = c.Writer.WriteString("data: 1\n\n")
+		c.Writer.Flush()
+		_, _ = c.Writer.WriteString("data: 2\n\n")
+	})
+	router.POST("/echo", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.String(http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+		c.Data(http.StatusOK, "text/plain", body)
+	})
+
+	do := func(req *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		return do(req)
+	}
+
+	w := get("/large", "gzip, deflate")
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	assert.Equal(t, `W/"v1"`, w.Header().Get("ETag"))
+	reader, err := gzip.NewReader(w.Body)
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(reader)
+	assert.Equal(t, large, string(data))
+
+	// 权重相同时按服务端偏好优先 br
+	w = get("/large", "gzip, deflate, br")
+	assert.Equal(t, "br", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	assert.Less(t, w.Body.Len(), len(large))
+	data, err = io.ReadAll(brotli.NewReader(w.Body))
+	assert.NoError(t, err)
+	assert.Equal(t, large, string(data))
+
+	// 按权重协商
+	w = get("/large", "gzip;q=0.5, deflate")
+	assert.Equal(t, "deflate", w.Header().Get("Content-Encoding"))
+	data, _ = io.ReadAll(flate.NewReader(w.Body))
+	assert.Equal(t, large, string(data))
+	w = get("/large", "gzip;q=0, identity")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, large, w.Body.String())
+
+	// 小于阈值与不可压缩类型原样输出
+	w = get("/small", "gzip")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "small", w.Body.String())
+	w = get("/binary", "gzip")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, large, w.Body.String())
+
+	// Flush 后立即压缩输出，不受阈值限制
+	w = get("/stream", "gzip")
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.True(t, w.Flushed)
+	reader, err = gzip.NewReader(w.Body)
+	assert.NoError(t, err)
+	data, _ = io.ReadAll(reader)
+	assert.Equal(t, "data: 1\n\ndata: 2\n\n", string(data))
+
+	// 编码器复用后输出仍正确
+	for i := 0; i < 3; i++ {
+		reader, err = gzip.NewReader(get("/large", "gzip").Body)
+		assert.NoError(t, err)
+		data, _ = io.ReadAll(reader)
+		assert.Equal(t, large, string(data))
+	}
+
+	// 请求 body 解压
+	gzipBody := func(s string) *bytes.Buffer {
+		buf := &bytes.Buffer{}
+		gw := gzip.NewWriter(buf)
+		_, _ = gw.Write([]byte(s))
+		_ = gw.Close()
+		return buf
+	}
+	req := httptest.NewRequest(http.MethodPost, "/echo", gzipBody("payload"))
+	req.Header.Set("Content-Encoding", "gzip")
+	w = do(req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "payload", w.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/echo", gzipBody(strings.Repeat("a", 2048)))
+	req.Header.Set("Content-Encoding", "gzip")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, do(req).Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("not gzip"))
+	req.Header.Set("Content-Encoding", "gzip")
+	assert.Equal(t, http.StatusBadRequest, do(req).Code)
+}
@@ -0,0 +1,92 @@
+package gin_server
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/log"
+	"github.com/ragpanda/go-toolkit/utils"
+)
+
+// ErrorRenderMW 在 handler 未写出响应时，将 c.Errors 中最后一个错误渲染为标准错误响应
+func ErrorRenderMW(c *gin.Context) {
+	c.Next()
+
+	if c.Writer.Written() || len(c.Errors) == 0 {
+		return
+	}
+	RenderError(c, c.Errors.Last().Err)
+}
+
+// ErrorHandler 将返回 error 的 handler 适配为 gin.HandlerFunc，错误按标准格式输出
+func ErrorHandler(handler func(c *gin.Context) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := handler(c); err != nil {
+			RenderError(c, err)
+		}
+	}
+}
+
+// RenderError 按错误码注册表输出错误响应，并以对应级别记录日志
+// 请求 Accept 包含 application/problem+json 时输出 RFC 7807 文档
+func RenderError(c *gin.Context, err error) {
+	if acceptProblem(c) {
+		RenderProblem(c, err)
+		return
+	}
+
+	status, body := bizerr.ToErrorBody(c, err)
+	logError(c, err, status, body.Code, body.LogID)
+	writeErrorBody(c, status, body)
+}
+
+// RenderProblem 以 application/problem+json 格式输出错误响应
+func RenderProblem(c *gin.Context, err error) {
+	problem := bizerr.ToProblemDetails(c, err, c.Request.URL.Path)
+	logError(c, err, problem.Status, problem.Code, problem.LogID)
+	writeProblem(c, problem)
+}
+
+// writeError 按 Accept 协商输出错误响应，不记录日志
+func writeError(c *gin.Context, err error) {
+	if acceptProblem(c) {
+		writeProblem(c, bizerr.ToProblemDetails(c, err, c.Request.URL.Path))
+		return
+	}
+	status, body := bizerr.ToErrorBody(c, err)
+	writeErrorBody(c, status, body)
+}
+
+func writeErrorBody(c *gin.Context, status int, body *bizerr.ErrorBody) {
+	setRetryAfter(c, body.Details)
+	c.AbortWithStatusJSON(status, body)
+}
+
+func writeProblem(c *gin.Context, problem *bizerr.ProblemDetails) {
+	setRetryAfter(c, problem.Details)
+	c.Abort()
+	c.Data(problem.Status, bizerr.ProblemContentType, utils.MustJsonEncodeBytes(problem))
+}
+
+func acceptProblem(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), bizerr.ProblemContentType)
+}
+
+func logError(c *gin.Context, err error, status int, code bizerr.ErrorCode, logID string) {
+	level := bizerr.GetCodeMeta(code).LogLevel
+	log.Log(c, level, "[error] api:%s %s %d code:%s err:%s, log_id:%s",
+		c.Request.Method, c.Request.URL.Path,
+		status, code, err.Error(), logID,
+	)
+}
+
+func setRetryAfter(c *gin.Context, details *bizerr.ErrorDetails) {
+	if details == nil || details.RetryInfo == nil {
+		return
+	}
+	retryAfter := details.RetryInfo.RetryAfter()
+	c.Header("Retry-After", strconv.FormatInt(int64(math.Ceil(retryAfter.Seconds())), 10))
+}
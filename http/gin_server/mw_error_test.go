@@ -0,0 +1,105 @@
+package gin_server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorRenderMW(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(BizDataMw, LocaleMW, ErrorRenderMW)
+
+	router.GET("/not_found", func(c *gin.Context) {
+		_ = c.Error(bizerr.ErrNotFound.WithMessage("user not found"))
+	})
+	router.GET("/not_found_default", func(c *gin.Context) {
+		_ = c.Error(bizerr.ErrNotFound)
+	})
+	router.GET("/internal", ErrorHandler(func(c *gin.Context) error {
+		return errors.New("mongo: connection refused")
+	}))
+	router.GET("/ok", ErrorHandler(func(c *gin.Context) error {
+		c.String(http.StatusOK, "OK")
+		return nil
+	}))
+
+	t.Run("Business Error", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/not_found", nil)
+		req.Header.Set(LogIDKey, "log-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		body := &bizerr.ErrorBody{}
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.NoError(t, utils.Unmarshal(w.Body.Bytes(), body))
+		assert.Equal(t, bizerr.NotFound, body.Code)
+		assert.Equal(t, "user not found", body.Message)
+		assert.Equal(t, "log-1", body.LogID)
+	})
+
+	t.Run("Unknown Error", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/internal", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		body := &bizerr.ErrorBody{}
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NoError(t, utils.Unmarshal(w.Body.Bytes(), body))
+		assert.Equal(t, bizerr.InternalError, body.Code)
+		assert.Empty(t, body.Message)
+		assert.NotEmpty(t, body.LogID)
+	})
+
+	t.Run("Problem Json", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/not_found", nil)
+		req.Header.Set("Accept", bizerr.ProblemContentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		problem := &bizerr.ProblemDetails{}
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, bizerr.ProblemContentType, w.Header().Get("Content-Type"))
+		assert.NoError(t, utils.Unmarshal(w.Body.Bytes(), problem))
+		assert.Equal(t, http.StatusNotFound, problem.Status)
+		assert.Equal(t, "Not Found", problem.Title)
+		assert.Equal(t, "user not found", problem.Detail)
+		assert.Equal(t, "/not_found", problem.Instance)
+		assert.Equal(t, bizerr.NotFound, problem.Code)
+	})
+
+	t.Run("Localized", func(t *testing.T) {
+		render := func(path, acceptLanguage string) string {
+			req := httptest.NewRequest("GET", path, nil)
+			req.Header.Set("Accept-Language", acceptLanguage)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			body := &bizerr.ErrorBody{}
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.NoError(t, utils.Unmarshal(w.Body.Bytes(), body))
+			return body.Message
+		}
+		assert.Equal(t, "请求的资源不存在", render("/not_found_default", "fr;q=0.9, zh-CN, en;q=0.8"))
+		// 自定义消息不被目录覆盖
+		assert.Equal(t, "user not found", render("/not_found", "zh-CN"))
+		// 目录不支持请求的语言时不做本地化
+		assert.Equal(t, "", render("/not_found_default", "fr"))
+		assert.Equal(t, "", matchLocale("fr, ja;q=0.5"))
+	})
+
+	t.Run("No Error", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/ok", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "OK", w.Body.String())
+	})
+}
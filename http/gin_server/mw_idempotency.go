@@ -0,0 +1,214 @@
+package gin_server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/biz"
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/log"
+	"github.com/ragpanda/go-toolkit/utils"
+	"github.com/ragpanda/go-toolkit/utils/idempotency"
+)
+
+const (
+	// IdempotencyKeyHeader 客户端传入的幂等键请求头
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader 响应为重放的已存储结果时设置为 true
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+type IdempotencyConfig struct {
+	Enable bool `yaml:"Enable" json:"Enable"`
+	// Methods 需要幂等处理的请求方法，默认 POST、PATCH
+	Methods []string `yaml:"Methods" json:"Methods"`
+	// MatchPathPrefixes 需要幂等处理的路径前缀，为空时处理所有路径
+	MatchPathPrefixes []string `yaml:"MatchPathPrefixes" json:"MatchPathPrefixes"`
+	// RequireKey 为 true 时匹配的请求缺少幂等键直接拒绝
+	RequireKey bool `yaml:"RequireKey" json:"RequireKey"`
+	// MaxKeyLength 幂等键长度上限，默认 255
+	MaxKeyLength int `yaml:"MaxKeyLength" json:"MaxKeyLength"`
+	// LockTTLSec 首个请求处理中的锁定时长，超时后视为失败允许重试，默认 60
+	LockTTLSec int64 `yaml:"LockTTLSec" json:"LockTTLSec"`
+	// TTLSec 响应结果保留时长，默认 86400
+	TTLSec int64 `yaml:"TTLSec" json:"TTLSec"`
+	// MaxBodyBytes 参与摘要计算的请求 body 上限，超出时拒绝，默认 10MB
+	MaxBodyBytes int64 `yaml:"MaxBodyBytes" json:"MaxBodyBytes"`
+	// MaxResponseBytes 存储的响应 body 上限，超出时不存储并释放幂等键，默认 1MB
+	MaxResponseBytes int `yaml:"MaxResponseBytes" json:"MaxResponseBytes"`
+}
+
+// 不随重放结果输出的响应头
+var idempotencySkipHeaders = []string{"Content-Length", "Date", "Set-Cookie", "Connection", "Transfer-Encoding"}
+
+// NewIdempotencyMW 创建幂等中间件：幂等键按 BizData.UserID 隔离，首个请求处理期间锁定该键，
+// 完成后存储响应状态码、响应头与 body，重复请求直接重放；同一幂等键携带不同请求内容时拒绝。
+// 5xx 响应、未输出的错误或 panic 时释放幂等键以允许重试。需放在鉴权中间件之后，store 为 nil 时使用进程内存储
+func NewIdempotencyMW(config *IdempotencyConfig, store idempotency.Store) gin.HandlerFunc {
+	if store == nil {
+		store = idempotency.NewMemoryStore()
+	}
+	methods := config.Methods
+	if len(methods) == 0 {
+		methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	maxKeyLength := config.MaxKeyLength
+	if maxKeyLength <= 0 {
+		maxKeyLength = 255
+	}
+	lockTTL := time.Duration(config.LockTTLSec) * time.Second
+	if lockTTL <= 0 {
+		lockTTL = time.Minute
+	}
+	ttl := time.Duration(config.TTLSec) * time.Second
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	maxBodyBytes := config.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = 10 << 20
+	}
+	maxResponseBytes := config.MaxResponseBytes
+	if maxResponseBytes <= 0 {
+		maxResponseBytes = 1 << 20
+	}
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if !utils.InSlice(methods, c.Request.Method) ||
+			(len(config.MatchPathPrefixes) != 0 && !hasAnyPrefix(path, config.MatchPathPrefixes)) {
+			c.Next()
+			return
+		}
+
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			if config.RequireKey {
+				RenderError(c, bizerr.ErrInvalidInput.WithMessage("missing "+IdempotencyKeyHeader+" header"))
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+		if len(key) > maxKeyLength {
+			RenderError(c, bizerr.ErrInvalidInput.WithMessage(IdempotencyKeyHeader+" header too long"))
+			c.Abort()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(io.LimitReader(c.Request.Body, maxBodyBytes+1))
+			if err != nil {
+				RenderError(c, bizerr.Wrap(err, bizerr.InvalidInput, "read request body failed"))
+				c.Abort()
+				return
+			}
+			if int64(len(body)) > maxBodyBytes {
+				RenderError(c, bizerr.ErrInvalidInput.WithMessage("request body too large for idempotency check"))
+				c.Abort()
+				return
+			}
+			c.Request.Body = readCloser{Reader: bytes.NewReader(body), Closer: c.Request.Body}
+		}
+
+		var userID string
+		if bizData := biz.GetBizData(c); bizData != nil {
+			userID = bizData.UserID
+		}
+		scopedKey := userID + ":" + key
+		requestHash := idempotencyRequestHash(c.Request.Method, c.Request.URL.RequestURI(), body)
+
+		owner, existing, err := store.Acquire(c, scopedKey, requestHash, lockTTL)
+		if err != nil {
+			RenderError(c, bizerr.Wrap(err, bizerr.InternalError, "acquire idempotency key failed"))
+			c.Abort()
+			return
+		}
+		if existing != nil {
+			switch {
+			case existing.RequestHash != requestHash:
+				RenderError(c, bizerr.ErrInvalidInput.WithMessage(IdempotencyKeyHeader+" has been used with a different request"))
+			case existing.State != idempotency.StateCompleted:
+				RenderError(c, bizerr.ErrConflict.
+					WithMessage("a request with the same "+IdempotencyKeyHeader+" is in progress").
+					WithRetryAfter(time.Second))
+			default:
+				replayIdempotentResponse(c, existing)
+			}
+			c.Abort()
+			return
+		}
+
+		writer := &bodyLogWriter{ResponseWriter: c.Writer, limit: maxResponseBytes}
+		c.Writer = writer
+		completed := false
+		defer func() {
+			c.Writer = writer.ResponseWriter
+			if completed {
+				return
+			}
+			// handler panic 或结果不可存储时释放幂等键
+			if err := store.Release(c, scopedKey, owner); err != nil {
+				log.Error(c, "release idempotency key %s failed: %v", key, err)
+			}
+		}()
+
+		c.Next()
+
+		// 交由 ErrorRenderMW 输出的错误尚未写入响应，不存储
+		status := writer.Status()
+		if (len(c.Errors) != 0 && !writer.Written()) ||
+			status >= http.StatusInternalServerError || writer.body.Len() > maxResponseBytes {
+			return
+		}
+		header := writer.Header().Clone()
+		for _, h := range idempotencySkipHeaders {
+			header.Del(h)
+		}
+		err = store.Complete(c, scopedKey, owner, &idempotency.Record{
+			RequestHash: requestHash,
+			StatusCode:  status,
+			Header:      header,
+			Body:        writer.body.Bytes(),
+		}, ttl)
+		if err == idempotency.ErrLockLost {
+			log.Warn(c, "idempotency key %s expired and was taken over before completing", key)
+			return
+		}
+		if err != nil {
+			log.Error(c, "store idempotency result of key %s failed: %v", key, err)
+			return
+		}
+		completed = true
+	}
+}
+
+func idempotencyRequestHash(method string, uri string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(strings.Join([]string{method, uri, ""}, "\n")))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replayIdempotentResponse(c *gin.Context, record *idempotency.Record) {
+	header := c.Writer.Header()
+	for k, v := range record.Header {
+		header[k] = append([]string(nil), v...)
+	}
+	header.Set(IdempotentReplayedHeader, "true")
+	c.Status(record.StatusCode)
+	if len(record.Body) == 0 {
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	_, _ = c.Writer.Write(record.Body)
+}
@@ -0,0 +1,112 @@
+package gin_server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyMW(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var calls int32
+	blocking := make(chan struct{})
+	entered := make(chan struct{})
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(UserKey, c.GetHeader("X-User"))
+	}, BizDataMw, ErrorRenderMW, NewIdempotencyMW(&IdempotencyConfig{
+		Enable:            true,
+		MatchPathPrefixes: []string{"/orders", "/pay"},
+	}, nil))
+	router.POST("/orders", func(c *gin.Context) {
+		n := atomic.AddInt32(&calls, 1)
+		c.Header("X-Order", "o1")
+		c.JSON(http.StatusCreated, gin.H{"n": n})
+	})
+	router.POST("/pay", func(c *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		switch c.Query("mode") {
+		case "block":
+			close(entered)
+			<-blocking
+			c.Status(http.StatusOK)
+		case "fail":
+			c.Status(http.StatusBadGateway)
+		case "error":
+			_ = c.Error(bizerr.ErrForbidden)
+		}
+	})
+
+	do := func(path, user, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("X-User", user)
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("/orders", "u1", "k1", `{"amount":1}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"n":1}`, w.Body.String())
+
+	// 重复请求重放已存储的结果
+	w = do("/orders", "u1", "k1", `{"amount":1}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"n":1}`, w.Body.String())
+	assert.Equal(t, "o1", w.Header().Get("X-Order"))
+	assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+
+	// 不同用户的同名幂等键互不影响
+	w = do("/orders", "u2", "k1", `{"amount":1}`)
+	assert.JSONEq(t, `{"n":2}`, w.Body.String())
+	assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+
+	// 同一幂等键请求内容不同时拒绝
+	w = do("/orders", "u1", "k1", `{"amount":2}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// 未携带幂等键时不处理
+	do("/orders", "u1", "", `{}`)
+	do("/orders", "u1", "", `{}`)
+	assert.EqualValues(t, 4, atomic.LoadInt32(&calls))
+
+	// 首个请求处理中时拒绝并发的重复请求
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w := do("/pay?mode=block", "u1", "k2", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+	}()
+	<-entered
+	w = do("/pay?mode=block", "u1", "k2", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	errBody := &bizerr.ErrorBody{}
+	assert.NoError(t, utils.Unmarshal(w.Body.Bytes(), errBody))
+	assert.Equal(t, bizerr.Conflict, errBody.Code)
+	close(blocking)
+	wg.Wait()
+	w = do("/pay?mode=block", "u1", "k2", "")
+	assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+
+	// 5xx 与未输出的错误不存储，允许重试
+	calls = 0
+	assert.Equal(t, http.StatusBadGateway, do("/pay?mode=fail", "u1", "k3", "").Code)
+	assert.Equal(t, http.StatusBadGateway, do("/pay?mode=fail", "u1", "k3", "").Code)
+	assert.Equal(t, http.StatusForbidden, do("/pay?mode=error", "u1", "k4", "").Code)
+	assert.Equal(t, http.StatusForbidden, do("/pay?mode=error", "u1", "k4", "").Code)
+	assert.EqualValues(t, 4, atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,103 @@
+package gin_server
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/biz"
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/utils"
+	"github.com/ragpanda/go-toolkit/utils/jwt"
+)
+
+const (
+	// UserKey gin 上下文中的用户标识，BizDataMw 据此填充 BizData.UserID
+	UserKey = "user"
+	// JWTClaimsKey gin 上下文中完整的 jwt.Claims
+	JWTClaimsKey = "jwt_claims"
+)
+
+type JWTAuthConfig struct {
+	Enable             bool `yaml:"Enable" json:"Enable"`
+	jwt.VerifierConfig `yaml:",inline"`
+
+	// SkipPaths 完全匹配时跳过鉴权
+	SkipPaths []string `yaml:"SkipPaths" json:"SkipPaths"`
+	// SkipPathPrefixes 匹配路径前缀时跳过鉴权
+	SkipPathPrefixes []string `yaml:"SkipPathPrefixes" json:"SkipPathPrefixes"`
+	// ClaimKeys 需要写入 gin 上下文与 BizData.Custom 的 claim
+	ClaimKeys []string `yaml:"ClaimKeys" json:"ClaimKeys"`
+}
+
+// NewJWTAuthMW 创建 JWT 鉴权中间件，校验 Authorization: Bearer 中的 token，
+// 将 sub 写入 BizData.UserID，失败时返回 ErrUnauthorized，需放在 BizDataMw 之后
+func NewJWTAuthMW(config *JWTAuthConfig) (gin.HandlerFunc, error) {
+	verifier, err := jwt.NewVerifier(&config.VerifierConfig)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if utils.InSlice(config.SkipPaths, path) || hasAnyPrefix(path, config.SkipPathPrefixes) {
+			c.Next()
+			return
+		}
+
+		token, ok := bearerToken(c.GetHeader("Authorization"))
+		if !ok {
+			RenderError(c, bizerr.ErrUnauthorized.WithMessage("missing bearer token"))
+			c.Abort()
+			return
+		}
+		claims, err := verifier.Verify(token)
+		if err != nil {
+			RenderError(c, err)
+			c.Abort()
+			return
+		}
+
+		subject := claims.Subject()
+		c.Set(UserKey, subject)
+		c.Set(JWTClaimsKey, claims)
+		bizData := biz.GetBizData(c)
+		if bizData != nil {
+			bizData.UserID = subject
+		}
+		for _, key := range config.ClaimKeys {
+			value, ok := claims[key]
+			if !ok {
+				continue
+			}
+			c.Set(key, value)
+			if bizData != nil {
+				bizData.SetKey(key, value)
+			}
+		}
+		c.Next()
+	}, nil
+}
+
+// GetJWTClaims 获取 NewJWTAuthMW 校验通过的 claims
+func GetJWTClaims(c *gin.Context) jwt.Claims {
+	claims, _ := c.Get(JWTClaimsKey)
+	result, _ := claims.(jwt.Claims)
+	return result
+}
+
+func bearerToken(header string) (string, bool) {
+	const prefix = "bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	token := strings.TrimSpace(header[len(prefix):])
+	return token, token != ""
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
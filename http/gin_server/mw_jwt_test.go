@@ -0,0 +1,79 @@
+package gin_server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/biz"
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/utils"
+	"github.com/ragpanda/go-toolkit/utils/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWTAuthMW(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jwtMW, err := NewJWTAuthMW(&JWTAuthConfig{
+		Enable:           true,
+		VerifierConfig:   jwt.VerifierConfig{Secret: "secret"},
+		SkipPaths:        []string{"/ping"},
+		SkipPathPrefixes: []string{"/public/"},
+		ClaimKeys:        []string{"tenant"},
+	})
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.Use(BizDataMw, jwtMW)
+	handler := func(c *gin.Context) {
+		bizData := biz.GetBizData(c)
+		c.JSON(http.StatusOK, gin.H{
+			"user":   bizData.UserID,
+			"tenant": bizData.GetKey("tenant"),
+			"ctx":    c.GetString(UserKey),
+		})
+	}
+	router.GET("/ping", handler)
+	router.GET("/public/doc", handler)
+	router.GET("/me", handler)
+
+	for _, path := range []string{"/ping", "/public/doc"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/me", nil))
+	body := &bizerr.ErrorBody{}
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.NoError(t, utils.Unmarshal(w.Body.Bytes(), body))
+	assert.Equal(t, bizerr.Unauthorized, body.Code)
+
+	// 默认拒绝不带 exp 的 token
+	token, err := jwt.SignHS256(jwt.Claims{"sub": "u1", "tenant": "t1"}, "secret")
+	assert.NoError(t, err)
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	token, err = jwt.SignHS256(jwt.Claims{"sub": "u1", "tenant": "t1", "exp": time.Now().Add(time.Hour).Unix()}, "secret")
+	assert.NoError(t, err)
+	req = httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user":"u1","tenant":"t1","ctx":"u1"}`, w.Body.String())
+
+	req = httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token+"x")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,74 @@
+package gin_server
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/biz"
+	"github.com/ragpanda/go-toolkit/bizerr"
+)
+
+// LocaleMW 根据 Accept-Language 选择错误消息目录中支持的语言并写入 BizData，BizData 已指定语言时保持不变
+func LocaleMW(c *gin.Context) {
+	bizData := biz.GetBizData(c)
+	if bizData == nil {
+		bizData = biz.NewBizData()
+		biz.SetBizDataToGinCtx(c, bizData)
+	}
+
+	if bizData.Locale == "" {
+		bizData.Locale = matchLocale(c.GetHeader("Accept-Language"))
+	}
+	c.Next()
+}
+
+// matchLocale 按权重依次匹配 Accept-Language 中的语言，目录均不支持时返回空
+func matchLocale(acceptLanguage string) string {
+	locales := parseAcceptLanguage(acceptLanguage)
+	catalog := bizerr.GetMessageCatalog()
+	for _, locale := range locales {
+		if _, ok := catalog.Match(locale); ok {
+			return locale
+		}
+	}
+	return ""
+}
+
+func parseAcceptLanguage(acceptLanguage string) []string {
+	type weightedLocale struct {
+		locale string
+		q      float64
+	}
+
+	var weighted []weightedLocale
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		locale := strings.TrimSpace(fields[0])
+		if locale == "" || locale == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			weighted = append(weighted, weightedLocale{locale: locale, q: q})
+		}
+	}
+
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].q > weighted[j].q
+	})
+	locales := make([]string, 0, len(weighted))
+	for _, w := range weighted {
+		locales = append(locales, w.locale)
+	}
+	return locales
+}
@@ -0,0 +1,80 @@
+package gin_server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/biz"
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/log"
+	"github.com/ragpanda/go-toolkit/metrics"
+)
+
+// PanicReporter 上报 panic 的钩子，err 为携带调用栈与 BizData 的 InternalError，errors.Unwrap 可取得 panic 的值
+type PanicReporter func(c *gin.Context, err bizerr.BusinessError)
+
+// NewRecoveryMW 创建 panic 恢复中间件：记录日志与调用栈、上报 metrics 与 reporter，并输出标准 InternalError 响应
+func NewRecoveryMW(reporters ...PanicReporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			cause, ok := recovered.(error)
+			if !ok {
+				cause = fmt.Errorf("%v", recovered)
+			}
+			err := bizerr.Wrap(cause, bizerr.InternalError, "panic recovered").WithStack(c)
+
+			var bizStr string
+			if bizData := biz.GetBizData(c); bizData != nil {
+				bizStr = bizData.String()
+			}
+			log.Error(c, "[panic] api:%s %s panic:%v, biz:%s\n%s",
+				c.Request.Method, c.Request.URL.Path, recovered, bizStr, err.StackTrace())
+			metrics.RecordAPIPanic(metrics.APIPanic{
+				Method: c.Request.Method,
+				Path:   c.FullPath(),
+			})
+			for _, reporter := range reporters {
+				reporter(c, err)
+			}
+
+			if isBrokenPipe(cause) {
+				_ = c.Error(err)
+				c.Abort()
+				return
+			}
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			writeError(c, err)
+		}()
+		c.Next()
+	}
+}
+
+// isBrokenPipe 客户端已断开连接时无需再输出响应
+func isBrokenPipe(err error) bool {
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	var syscallErr *os.SyscallError
+	if !errors.As(opErr, &syscallErr) {
+		return false
+	}
+	msg := strings.ToLower(syscallErr.Error())
+	return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+}
@@ -0,0 +1,43 @@
+package gin_server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoveryMW(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var reported bizerr.BusinessError
+	router := gin.New()
+	router.Use(NewRecoveryMW(func(c *gin.Context, err bizerr.BusinessError) {
+		reported = err
+	}), BizDataMw)
+	router.GET("/panic", func(c *gin.Context) {
+		panic("something wrong")
+	})
+
+	req := httptest.NewRequest("GET", "/panic", nil)
+	req.Header.Set(LogIDKey, "log-panic")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	body := &bizerr.ErrorBody{}
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NoError(t, utils.Unmarshal(w.Body.Bytes(), body))
+	assert.Equal(t, bizerr.InternalError, body.Code)
+	assert.Equal(t, "log-panic", body.LogID)
+
+	assert.NotNil(t, reported)
+	assert.True(t, errors.Is(reported, bizerr.ErrInternalError))
+	assert.Equal(t, "something wrong", errors.Unwrap(reported).Error())
+	assert.Contains(t, reported.StackTrace(), "TestRecoveryMW")
+	assert.Equal(t, "log-panic", reported.BizData().LogID)
+}
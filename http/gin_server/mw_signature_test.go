@@ -0,0 +1,94 @@
+package gin_server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/http/client"
+	"github.com/ragpanda/go-toolkit/utils/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatureMW(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	router := gin.New()
+	router.Use(NewSignatureMW(&SignatureConfig{
+		Enable: true,
+		VerifierConfig: signature.VerifierConfig{
+			Keys: map[string]string{"k1": "old-secret", "k2": "new-secret"},
+		},
+		SkipPaths: []string{"/ping"},
+	}, nil))
+	var lastReq *http.Request
+	var lastBody []byte
+	router.POST("/echo", func(c *gin.Context) {
+		lastReq = c.Request.Clone(c)
+		lastBody, _ = c.GetRawData()
+		c.JSON(http.StatusOK, gin.H{"key_id": c.GetString(SignatureKeyIDKey)})
+	})
+	router.POST("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	c := client.NewHttpClient(client.HttpOptionalArgs{})
+
+	// 轮换期间新旧 key 均可通过
+	for _, keyID := range []string{"k1", "k2"} {
+		secret := map[string]string{"k1": "old-secret", "k2": "new-secret"}[keyID]
+		resp := &struct {
+			KeyID string `json:"key_id"`
+		}{}
+		result := c.DoJson(ctx, server.URL+"/echo?a=1", map[string]int{"n": 1}, client.WithSigner(keyID, secret))
+		assert.NoError(t, result.Unmarshal(ctx, resp))
+		assert.Equal(t, keyID, resp.KeyID)
+		assert.Equal(t, `{"n":1}`, string(lastBody))
+	}
+
+	result := c.DoJson(ctx, server.URL+"/echo", nil)
+	be, ok := result.DecodeRemoteError(ctx)
+	assert.True(t, ok)
+	assert.True(t, bizerr.Is(be, bizerr.ErrUnauthorized))
+
+	result = c.DoJson(ctx, server.URL+"/echo", nil, client.WithSigner("k1", "wrong"))
+	assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
+
+	result = c.DoJson(ctx, server.URL+"/ping", nil)
+	assert.NoError(t, result.Error())
+
+	// 重放上一次请求
+	replay := httptest.NewRequest(http.MethodPost, "/echo?a=1", bytes.NewReader(lastBody))
+	replay.Header = lastReq.Header.Clone()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, replay)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// 篡改 body
+	tampered := httptest.NewRequest(http.MethodPost, "/echo?a=1", bytes.NewReader([]byte(`{"n":2}`)))
+	tampered.Header = lastReq.Header.Clone()
+	tampered.Header.Set(signature.HeaderNonce, "another-nonce")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, tampered)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// 超出时间窗口
+	stale := httptest.NewRequest(http.MethodPost, "/echo", nil)
+	timestamp := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	stale.Header.Set(signature.HeaderKeyID, "k1")
+	stale.Header.Set(signature.HeaderTimestamp, timestamp)
+	stale.Header.Set(signature.HeaderNonce, "n1")
+	stale.Header.Set(signature.HeaderSignature, signature.Compute("old-secret", signature.StringToSign(stale, timestamp, "n1", nil)))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, stale)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -8,36 +8,82 @@ import (
 	"github.com/ragpanda/go-toolkit/metrics"
 )
 
+// BusinessCategoryKey gin 上下文中路由的业务分类，由 WithBusinessCategory 设置
+const BusinessCategoryKey = "business_category"
+
+// unmatchedPath 未匹配任何路由的请求统一使用该 Path 标签，避免扫描类请求产生大量序列
+const unmatchedPath = "unmatched"
+
+// SuccessClassifier 判断请求是否成功，在 handler 执行完毕后调用
+type SuccessClassifier func(c *gin.Context) bool
+
+// SuccessIf2xx3xx 状态码为 2xx/3xx 视为成功，StatMW 默认使用
+func SuccessIf2xx3xx(c *gin.Context) bool {
+	status := c.Writer.Status()
+	return 200 <= status && status < 400
+}
+
+// SuccessUnlessServerError 仅 5xx 视为失败，适合按可用性统计的场景
+func SuccessUnlessServerError(c *gin.Context) bool {
+	return c.Writer.Status() < 500
+}
+
+// WithBusinessCategory 路由级中间件，为该路由的统计数据标注业务分类
+//
+//	r.GET("/orders/:id", gin_server.WithBusinessCategory("order"), getOrder)
+func WithBusinessCategory(category string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(BusinessCategoryKey, category)
+		c.Next()
+	}
+}
+
+// StatMW 使用默认成功判定的统计中间件
 func StatMW(c *gin.Context) {
-	start := time.Now()
-	defer func() {
-		labels := metrics.APIRequest{
-			Method:           c.Request.Method,
-			Path:             c.Request.URL.Path,
-			Success:          false,
-			Duration:         0,
-			BusinessCategory: "",
-		}
-		end := time.Now()
-		labels.Duration = end.Sub(start)
+	defaultStatMW(c)
+}
 
-		if c.Request.Response != nil {
-			labels.StatusCode = c.Request.Response.StatusCode
-			if 200 < c.Request.Response.StatusCode && c.Request.Response.StatusCode < 400 {
-				labels.Success = true
-			}
-		} else {
-			labels.StatusCode = 1
-			labels.Success = false
+var defaultStatMW = NewStatMW(nil)
+
+// NewStatMW 创建统计中间件，以路由模板作为 Path 标签，记录状态码、耗时、响应大小与进行中请求数，
+// isSuccess 为 nil 时使用 SuccessIf2xx3xx
+func NewStatMW(isSuccess SuccessClassifier) gin.HandlerFunc {
+	if isSuccess == nil {
+		isSuccess = SuccessIf2xx3xx
+	}
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.FullPath()
+		if path == "" {
+			path = unmatchedPath
 		}
+		finish := metrics.StartAPIRequest(metrics.APIInFlight{
+			Method: c.Request.Method,
+			Path:   path,
+		})
+		defer func() {
+			finish()
+			labels := metrics.APIRequest{
+				Method:           c.Request.Method,
+				Path:             path,
+				StatusCode:       c.Writer.Status(),
+				Success:          isSuccess(c),
+				Duration:         time.Since(start),
+				BusinessCategory: c.GetString(BusinessCategoryKey),
+				ResponseSize:     c.Writer.Size(),
+			}
+			if labels.ResponseSize < 0 {
+				labels.ResponseSize = 0
+			}
 
-		metrics.RecordAPIRequest(labels)
-		log.Info(c, "[stat] api:%s %s %d %v reqs:%d, log_id:%s",
-			labels.Method, labels.Path,
-			labels.StatusCode, labels.Duration,
-			c.Request.ContentLength,
-			c.Request.Header.Get("X-Log-Id"),
-		)
-	}()
-	c.Next()
+			metrics.RecordAPIRequest(labels)
+			log.Info(c, "[stat] api:%s %s %d %v reqs:%d resps:%d, log_id:%s",
+				labels.Method, c.Request.URL.Path,
+				labels.StatusCode, labels.Duration,
+				c.Request.ContentLength, labels.ResponseSize,
+				c.Request.Header.Get("X-Log-Id"),
+			)
+		}()
+		c.Next()
+	}
 }
@@ -0,0 +1,49 @@
+package gin_server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	go_metric "github.com/hashicorp/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatMW(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sink := go_metric.NewInmemSink(time.Minute, time.Minute)
+	config := go_metric.DefaultConfig("test")
+	config.EnableHostname = false
+	config.EnableRuntimeMetrics = false
+	_, err := go_metric.NewGlobal(config, sink)
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.Use(NewStatMW(SuccessUnlessServerError))
+	router.GET("/users/:id", WithBusinessCategory("user"), func(c *gin.Context) {
+		if c.Param("id") == "0" {
+			c.String(http.StatusNotFound, "none")
+			return
+		}
+		c.String(http.StatusOK, "hello")
+	})
+
+	for _, path := range []string{"/users/1", "/users/2", "/users/0", "/missing/1", "/missing/2"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	data := sink.Data()
+	assert.NotEmpty(t, data)
+	counters := data[len(data)-1].Counters
+	assert.Equal(t, 2, counters["test.api.requests;Method=GET;Path=/users/:id;StatusCode=200;Success=true;BusinessCategory=user"].Count)
+	assert.Equal(t, 1, counters["test.api.requests;Method=GET;Path=/users/:id;StatusCode=404;Success=true;BusinessCategory=user"].Count)
+	assert.Equal(t, 2, counters["test.api.requests;Method=GET;Path=unmatched;StatusCode=404;Success=true;BusinessCategory="].Count)
+
+	samples := data[len(data)-1].Samples
+	assert.Equal(t, float64(10), samples["test.api.response_size;Method=GET;Path=/users/:id;StatusCode=200;Success=true;BusinessCategory=user"].Sum)
+	assert.Equal(t, float32(0), data[len(data)-1].Gauges["test.api.in_flight;Method=GET;Path=/users/:id"].Value)
+}
@@ -0,0 +1,165 @@
+package gin_server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/metrics"
+	"github.com/ragpanda/go-toolkit/utils"
+)
+
+type TimeoutConfig struct {
+	Enable bool `yaml:"Enable" json:"Enable"`
+	// DefaultMillSec 未匹配任何规则时的超时时间，为 0 时不限制
+	DefaultMillSec int64 `yaml:"DefaultMillSec" json:"DefaultMillSec"`
+	// Rules 按路径前缀设置超时时间，按顺序匹配第一条
+	Rules []*TimeoutRule `yaml:"Rules" json:"Rules"`
+}
+
+type TimeoutRule struct {
+	// MatchPathPrefix 匹配路径前缀
+	MatchPathPrefix string `yaml:"MatchPathPrefix" json:"MatchPathPrefix"`
+	// TimeoutMillSec 超时时间，为 0 时该前缀不限制
+	TimeoutMillSec int64 `yaml:"TimeoutMillSec" json:"TimeoutMillSec"`
+}
+
+// NewTimeoutMW 创建按路径前缀配置的超时中间件，见 WithTimeout
+func NewTimeoutMW(config *TimeoutConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := time.Duration(config.DefaultMillSec) * time.Millisecond
+		for _, rule := range config.Rules {
+			if strings.HasPrefix(c.Request.URL.Path, rule.MatchPathPrefix) {
+				timeout = time.Duration(rule.TimeoutMillSec) * time.Millisecond
+				break
+			}
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+		handleWithTimeout(c, timeout)
+	}
+}
+
+// WithTimeout 为路由组设置超时时间：请求 ctx 带上 deadline，超时后输出 ErrTimeout 并记录 metrics，
+// 之后 handler 的写入被丢弃。响应在 handler 结束前先写入缓冲区，因此不适用于流式响应。
+// deadline 只设置在 c.Request.Context() 上，自建 engine 需开启 ContextWithFallback 后 c.Done() 才能感知超时。
+//
+//	api := engine.Group("/api", gin_server.WithTimeout(3*time.Second))
+func WithTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handleWithTimeout(c, timeout)
+	}
+}
+
+func handleWithTimeout(c *gin.Context, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+	c.Request = c.Request.WithContext(ctx)
+
+	origin := c.Writer
+	tw := newTimeoutWriter(origin, ctx)
+	c.Writer = tw
+
+	done := make(chan struct{})
+	var panicked interface{}
+	go func() {
+		defer close(done)
+		defer func() {
+			panicked = recover()
+		}()
+		c.Next()
+	}()
+
+	select {
+	case <-done:
+		tw.lock.Lock()
+		timedOut := tw.timedOut
+		if !timedOut {
+			tw.flushTo(origin)
+		}
+		tw.lock.Unlock()
+		if timedOut {
+			writeTimeout(c, origin, timeout)
+		}
+	case <-ctx.Done():
+		tw.lock.Lock()
+		tw.timedOut = true
+		tw.lock.Unlock()
+		writeTimeout(c, origin, timeout)
+		// 等待 handler 退出后再返回，避免 gin.Context 被回收复用时 handler 仍在访问
+		<-done
+	}
+
+	c.Writer = origin
+	if panicked != nil {
+		panic(panicked)
+	}
+}
+
+// writeTimeout 直接写入原始 writer，此时 handler 可能仍在运行，不能通过 c.Writer 输出
+func writeTimeout(c *gin.Context, w gin.ResponseWriter, timeout time.Duration) {
+	err := bizerr.ErrTimeout.WithMessage(fmt.Sprintf("request timeout after %v", timeout))
+	path := c.FullPath()
+	if path == "" {
+		path = unmatchedPath
+	}
+	metrics.RecordAPITimeout(metrics.APITimeout{
+		Method: c.Request.Method,
+		Path:   path,
+	})
+
+	var status int
+	var contentType string
+	var data []byte
+	if acceptProblem(c) {
+		problem := bizerr.ToProblemDetails(c, err, c.Request.URL.Path)
+		logError(c, err, problem.Status, problem.Code, problem.LogID)
+		status, contentType, data = problem.Status, bizerr.ProblemContentType, utils.MustJsonEncodeBytes(problem)
+	} else {
+		var body *bizerr.ErrorBody
+		status, body = bizerr.ToErrorBody(c, err)
+		logError(c, err, status, body.Code, body.LogID)
+		contentType, data = "application/json; charset=utf-8", utils.MustJsonEncodeBytes(body)
+	}
+
+	if w.Written() {
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+	w.Flush()
+}
+
+// timeoutWriter 缓冲 handler 的响应，handler 按时结束时再写出，超时后拒绝一切写入
+type timeoutWriter struct {
+	*bufferedWriter
+
+	ctx      context.Context
+	timedOut bool
+}
+
+func newTimeoutWriter(origin gin.ResponseWriter, ctx context.Context) *timeoutWriter {
+	w := &timeoutWriter{bufferedWriter: newBufferedWriter(origin), ctx: ctx}
+	w.rejectWrite = w.checkTimeout
+	return w
+}
+
+// checkTimeout handler 被 ctx 超时唤醒时可能先于中间件写入，需直接检查 deadline
+func (w *timeoutWriter) checkTimeout() error {
+	if !w.timedOut && w.ctx.Err() == context.DeadlineExceeded {
+		w.timedOut = true
+	}
+	if w.timedOut {
+		return http.ErrHandlerTimeout
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package gin_server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeoutMW(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	lateWrite := make(chan error, 1)
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(BizDataMw, ErrorRenderMW, NewTimeoutMW(&TimeoutConfig{
+		Enable:         true,
+		DefaultMillSec: 1000,
+		Rules: []*TimeoutRule{
+			{MatchPathPrefix: "/slow", TimeoutMillSec: 20},
+			{MatchPathPrefix: "/blocking", TimeoutMillSec: 20},
+		},
+	}))
+	router.GET("/fast", func(c *gin.Context) {
+		_, ok := c.Request.Context().Deadline()
+		assert.True(t, ok)
+		c.Header("X-Custom", "1")
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+	router.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		_, err := c.Writer.Write([]byte("late"))
+		lateWrite <- err
+	})
+	handlerDone := make(chan struct{})
+	router.GET("/blocking", func(c *gin.Context) {
+		defer close(handlerDone)
+		<-c.Done()
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		_ = c.Error(bizerr.ErrNotFound)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-Custom"))
+	assert.JSONEq(t, `{"ok":true}`, w.Body.String())
+
+	start := time.Now()
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	assert.Less(t, time.Since(start), time.Second)
+	body := &bizerr.ErrorBody{}
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.NoError(t, utils.Unmarshal(w.Body.Bytes(), body))
+	assert.Equal(t, bizerr.Timeout, body.Code)
+	assert.Equal(t, http.ErrHandlerTimeout, <-lateWrite)
+
+	// a handler waiting on the gin context itself is woken up by the deadline
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/blocking", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	select {
+	case <-handlerDone:
+	case <-time.After(time.Second):
+		assert.Fail(t, "handler blocking on c.Done() did not return")
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
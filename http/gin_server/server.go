@@ -11,8 +11,14 @@ import (
 
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/health"
 	"github.com/ragpanda/go-toolkit/log"
 	"github.com/ragpanda/go-toolkit/log/consts"
+	"github.com/ragpanda/go-toolkit/utils/httpcache"
+	"github.com/ragpanda/go-toolkit/utils/idempotency"
+	"github.com/ragpanda/go-toolkit/utils/signature"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 type GinHttpServer struct {
@@ -21,6 +27,19 @@ type GinHttpServer struct {
 	once   sync.Once
 	server *http.Server
 	engine *gin.Engine
+
+	panicReporters []PanicReporter
+	nonceStore     signature.NonceStore
+	idemStore      idempotency.Store
+	cacheStore     httpcache.Store
+	cache          *ResponseCache
+	isSuccess      SuccessClassifier
+	health         *health.Registry
+	hooks          []*Hook
+
+	adminServer    *http.Server
+	adminEngine    *gin.Engine
+	metricsHandler http.Handler
 }
 
 func NewGinHttpServer(config *GinConfig) *GinHttpServer {
@@ -29,6 +48,7 @@ func NewGinHttpServer(config *GinConfig) *GinHttpServer {
 		once:   sync.Once{},
 		server: nil,
 		engine: nil,
+		health: health.GetGlobal(),
 	}
 	return &serv
 }
@@ -36,29 +56,77 @@ func NewGinHttpServer(config *GinConfig) *GinHttpServer {
 func (self *GinHttpServer) Init() *GinHttpServer {
 	self.once.Do(func() {
 		self.fillDefault(self.config)
-		self.engine = gin.Default()
+		self.engine = gin.New()
+		// c.Done()/c.Deadline() 等读取 c.Request.Context()，handler 可直接用 c 感知超时
+		self.engine.ContextWithFallback = true
+		self.engine.Use(gin.Logger(), NewRecoveryMW(self.panicReporters...))
 		gin.DefaultWriter = log.GetLoggerWriter(log.GetGlobal(), consts.LogLevelInfo)
 		gin.DefaultErrorWriter = log.GetLoggerWriter(log.GetGlobal(), consts.LogLevelWarn)
 		if self.config.Mode != "" {
 			gin.SetMode(self.config.Mode)
 		}
 
-		if self.config.EnablePprof {
+		// 配置管理端口时 pprof 仅注册在管理端口上
+		if self.config.EnablePprof && !self.adminEnabled() {
 			pprof.Register(self.engine, self.config.ProfilePath)
 		}
+		if self.adminEnabled() {
+			if err := self.initAdmin(); err != nil {
+				log.Error(context.Background(), "init admin server failed %s", err.Error())
+				panic(err)
+			}
+		}
+		if healthConfig := self.config.Health; healthConfig != nil && healthConfig.Enable {
+			RegisterHealthRoutes(self.engine, self.health, healthConfig)
+		}
 		if self.config.EnableBaseMw {
-			self.engine.Use(BizDataMw, StatMW)
+			self.engine.Use(BizDataMw, LocaleMW, NewStatMW(self.isSuccess), ErrorRenderMW)
+		}
+		if tlsConfig := self.config.TLS; tlsConfig != nil && tlsConfig.Enable && tlsConfig.ClientCAFile != "" {
+			self.engine.Use(ClientIdentityMW)
+		}
+		if compressConfig := self.config.Compress; compressConfig != nil && compressConfig.Enable {
+			self.engine.Use(NewCompressMW(compressConfig))
 		}
 
 		if corsConfig := self.config.CORS; corsConfig != nil && corsConfig.Enable {
 			self.engine.Use(NewCorsMW(corsConfig))
 		}
+		if bodyLogConfig := self.config.BodyLog; bodyLogConfig != nil && bodyLogConfig.Enable {
+			self.engine.Use(NewBodyLogMW(bodyLogConfig))
+		}
+		if jwtConfig := self.config.JWTAuth; jwtConfig != nil && jwtConfig.Enable {
+			jwtMW, err := NewJWTAuthMW(jwtConfig)
+			if err != nil {
+				// 鉴权配置错误时不能放行请求
+				log.Error(context.Background(), "init jwt auth failed %s", err.Error())
+				panic(err)
+			}
+			self.engine.Use(jwtMW)
+		}
+		if signatureConfig := self.config.Signature; signatureConfig != nil && signatureConfig.Enable {
+			self.engine.Use(NewSignatureMW(signatureConfig, self.nonceStore))
+		}
+		self.cache = NewResponseCache(self.config.Cache, self.cacheStore)
+		if cacheConfig := self.config.Cache; cacheConfig != nil && cacheConfig.Enable {
+			self.engine.Use(self.cache.MW())
+		}
+		if idemConfig := self.config.Idempotency; idemConfig != nil && idemConfig.Enable {
+			self.engine.Use(NewIdempotencyMW(idemConfig, self.idemStore))
+		}
+		if timeoutConfig := self.config.Timeout; timeoutConfig != nil && timeoutConfig.Enable {
+			self.engine.Use(NewTimeoutMW(timeoutConfig))
+		}
 
+		var handler http.Handler = self.engine
+		if self.config.EnableH2C && !self.tlsEnabled() {
+			handler = h2c.NewHandler(self.engine, &http2.Server{})
+		}
 		self.server = &http.Server{
 			Addr:           self.config.Addr,
-			Handler:        self.engine,
-			ReadTimeout:    60 * time.Second,
-			WriteTimeout:   60 * time.Second,
+			Handler:        handler,
+			ReadTimeout:    time.Duration(self.config.ReadTimeoutSec) * time.Second,
+			WriteTimeout:   time.Duration(self.config.WriteTimeoutSec) * time.Second,
 			MaxHeaderBytes: 1 << 20,
 		}
 
@@ -66,43 +134,148 @@ func (self *GinHttpServer) Init() *GinHttpServer {
 	return self
 }
 
+// AddPanicReporter 添加 panic 上报钩子，需在 Init 前调用
+func (self *GinHttpServer) AddPanicReporter(reporter PanicReporter) *GinHttpServer {
+	self.panicReporters = append(self.panicReporters, reporter)
+	return self
+}
+
+// SetNonceStore 设置请求签名校验使用的 nonce 存储，需在 Init 前调用
+func (self *GinHttpServer) SetNonceStore(store signature.NonceStore) *GinHttpServer {
+	self.nonceStore = store
+	return self
+}
+
+// SetIdempotencyStore 设置幂等中间件的结果存储，多实例部署时应使用共享存储，需在 Init 前调用
+func (self *GinHttpServer) SetIdempotencyStore(store idempotency.Store) *GinHttpServer {
+	self.idemStore = store
+	return self
+}
+
+// SetCacheStore 设置响应缓存的存储，默认使用进程内 LRU，需在 Init 前调用
+func (self *GinHttpServer) SetCacheStore(store httpcache.Store) *GinHttpServer {
+	self.cacheStore = store
+	return self
+}
+
+// GetResponseCache 获取响应缓存，用于单个路由开启缓存或按标签失效，Init 后可用
+func (self *GinHttpServer) GetResponseCache() *ResponseCache {
+	return self.cache
+}
+
+// SetSuccessClassifier 设置 StatMW 的成功判定，需在 Init 前调用
+func (self *GinHttpServer) SetSuccessClassifier(isSuccess SuccessClassifier) *GinHttpServer {
+	self.isSuccess = isSuccess
+	return self
+}
+
+// SetHealthRegistry 设置健康检查注册表，默认使用 health.GetGlobal()，需在 Init 前调用
+func (self *GinHttpServer) SetHealthRegistry(registry *health.Registry) *GinHttpServer {
+	self.health = registry
+	return self
+}
+
+func (self *GinHttpServer) GetHealth() *health.Registry {
+	return self.health
+}
+
+// Shutdown 同时关闭业务端口与管理端口
 func (self *GinHttpServer) Shutdown(ctx context.Context) error {
-	return self.server.Shutdown(ctx)
+	if self.adminServer == nil {
+		return self.server.Shutdown(ctx)
+	}
+	adminErr := make(chan error, 1)
+	go func() {
+		adminErr <- self.adminServer.Shutdown(ctx)
+	}()
+	err := self.server.Shutdown(ctx)
+	if e := <-adminErr; e != nil && err == nil {
+		err = e
+	}
+	return err
 }
 
+// Run 执行 OnStart 钩子后开始监听，收到 SIGINT/SIGTERM 或 ctx 结束时按 gracefulStop 的顺序退出
 func (self *GinHttpServer) Run(ctx context.Context) error {
-	var e error
-	positiveExit := make(chan struct{}, 1)
+	if err := self.runStartHooks(ctx); err != nil {
+		return err
+	}
+
+	serveErr := make(chan error, 2)
 	go func() {
-		defer func() {
-			positiveExit <- struct{}{}
-		}()
-		err := self.server.ListenAndServe()
+		err := self.listenAndServe(ctx)
 		if err != nil && err != http.ErrServerClosed {
 			log.Error(ctx, "http server start failed %s", err.Error())
-			e = err
+			serveErr <- err
+			return
 		}
+		serveErr <- nil
 	}()
+	if self.adminServer != nil {
+		go func() {
+			err := self.adminServer.ListenAndServe()
+			if err != nil && err != http.ErrServerClosed {
+				log.Error(ctx, "admin server start failed %s", err.Error())
+				serveErr <- err
+			}
+		}()
+	}
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
 	select {
 	case <-quit:
+	case <-ctx.Done():
+	case err := <-serveErr:
 		log.Info(ctx, "Server is shutting down")
-		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(self.config.GracefulExitSec)*time.Second)
+		stopCtx, cancel := context.WithTimeout(context.Background(), time.Duration(self.config.GracefulExitSec)*time.Second)
 		defer cancel()
-		if err := self.server.Shutdown(ctx); err != nil {
-			log.Error(ctx, "Server Shutdown:", err)
-			e = err
-		}
-	case <-positiveExit:
-		log.Info(ctx, "Server is shutting down")
-
+		_ = self.Shutdown(stopCtx)
+		self.runStopHooks(stopCtx, self.hooks)
+		return err
 	}
 
+	log.Info(ctx, "Server is shutting down")
+	e := self.gracefulStop(context.Background())
+	if err := <-serveErr; err != nil {
+		e = err
+	}
 	return e
 }
 
+func (self *GinHttpServer) tlsEnabled() bool {
+	return self.config.TLS != nil && self.config.TLS.Enable
+}
+
+// listenAndServe 开启 TLS 时按配置加载证书，并在服务期间定期检查证书文件变更
+func (self *GinHttpServer) listenAndServe(ctx context.Context) error {
+	if !self.tlsEnabled() {
+		return self.server.ListenAndServe()
+	}
+
+	reloader, err := newCertReloader(self.config.TLS)
+	if err != nil {
+		return err
+	}
+	tlsConfig, err := reloader.tlsConfig()
+	if err != nil {
+		return err
+	}
+	self.server.TLSConfig = tlsConfig
+
+	if self.config.TLS.ReloadIntervalSec >= 0 {
+		interval := time.Duration(self.config.TLS.ReloadIntervalSec) * time.Second
+		if interval == 0 {
+			interval = 30 * time.Second
+		}
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go reloader.watch(watchCtx, interval)
+	}
+	return self.server.ListenAndServeTLS("", "")
+}
+
 func (self *GinHttpServer) GetEngine() *gin.Engine {
 	return self.engine
 }
@@ -115,10 +288,27 @@ func (self *GinHttpServer) fillDefault(config *GinConfig) {
 	if config.Addr == "" {
 		config.Addr = ":8080"
 	}
+	if config.ReadTimeoutSec == 0 {
+		config.ReadTimeoutSec = 60
+	}
+	if config.WriteTimeoutSec == 0 {
+		config.WriteTimeoutSec = 60
+	}
 	if config.GracefulExitSec == 0 {
 		config.GracefulExitSec = 10
 	}
 	if config.ProfilePath == "" {
 		config.ProfilePath = "/debug/pprof"
 	}
+	if config.Admin != nil && config.Admin.MetricsPath == "" {
+		config.Admin.MetricsPath = "/metrics"
+	}
+	if config.Health != nil {
+		if config.Health.LivenessPath == "" {
+			config.Health.LivenessPath = "/healthz"
+		}
+		if config.Health.ReadinessPath == "" {
+			config.Health.ReadinessPath = "/readyz"
+		}
+	}
 }
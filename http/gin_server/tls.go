@@ -0,0 +1,200 @@
+package gin_server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/biz"
+	"github.com/ragpanda/go-toolkit/log"
+)
+
+// ClientIdentityKey gin 上下文与 BizData.Custom 中已校验的客户端证书身份
+const ClientIdentityKey = "client_identity"
+
+type TLSConfig struct {
+	Enable   bool   `yaml:"Enable" json:"Enable"`
+	CertFile string `yaml:"CertFile" json:"CertFile"`
+	KeyFile  string `yaml:"KeyFile" json:"KeyFile"`
+	// MinVersion 最低 TLS 版本，可选 1.0/1.1/1.2/1.3，默认 1.2
+	MinVersion string `yaml:"MinVersion" json:"MinVersion"`
+	// ClientCAFile 客户端 CA，设置后开启 mTLS
+	ClientCAFile string `yaml:"ClientCAFile" json:"ClientCAFile"`
+	// ClientCertOptional 为 true 时允许不带证书的客户端，带证书时仍需校验通过
+	ClientCertOptional bool `yaml:"ClientCertOptional" json:"ClientCertOptional"`
+	// ReloadIntervalSec 检查证书文件变更的间隔，默认 30，小于 0 时不重新加载
+	ReloadIntervalSec int64 `yaml:"ReloadIntervalSec" json:"ReloadIntervalSec"`
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// certReloader 持有当前证书与客户端 CA，文件修改时间变化时重新加载，加载失败时继续使用旧证书
+type certReloader struct {
+	config *TLSConfig
+
+	lock      sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+}
+
+func newCertReloader(config *TLSConfig) (*certReloader, error) {
+	r := &certReloader{config: config}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) files() []string {
+	files := []string{r.config.CertFile, r.config.KeyFile}
+	if r.config.ClientCAFile != "" {
+		files = append(files, r.config.ClientCAFile)
+	}
+	return files
+}
+
+func (r *certReloader) load() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return err
+	}
+	var clientCAs *x509.CertPool
+	if r.config.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate found in %s", r.config.ClientCAFile)
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	return nil
+}
+
+func (r *certReloader) changed() bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			// 证书轮换过程中文件可能短暂不存在，下次再检查
+			return false
+		}
+		if !info.ModTime().Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *certReloader) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.load(); err != nil {
+				log.Error(ctx, "reload tls certificate failed %s", err.Error())
+				continue
+			}
+			log.Info(ctx, "tls certificate reloaded")
+		}
+	}
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.cert, nil
+}
+
+// tlsConfig 每次握手通过 GetConfigForClient 取得最新的证书与客户端 CA
+func (r *certReloader) tlsConfig() (*tls.Config, error) {
+	minVersion := uint16(tls.VersionTLS12)
+	if r.config.MinVersion != "" {
+		v, ok := tlsVersions[r.config.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tls min version %q", r.config.MinVersion)
+		}
+		minVersion = v
+	}
+	clientAuth := tls.NoClientCert
+	if r.config.ClientCAFile != "" {
+		clientAuth = tls.RequireAndVerifyClientCert
+		if r.config.ClientCertOptional {
+			clientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	base := &tls.Config{
+		MinVersion:     minVersion,
+		ClientAuth:     clientAuth,
+		GetCertificate: r.getCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		config := base.Clone()
+		config.GetConfigForClient = nil
+		r.lock.RLock()
+		config.ClientCAs = r.clientCAs
+		r.lock.RUnlock()
+		return config, nil
+	}
+	return base, nil
+}
+
+// ClientIdentityMW 将 mTLS 已校验的客户端证书身份写入 gin 上下文与 BizData，
+// 优先使用第一个 URI SAN（如 SPIFFE ID），否则使用 CommonName，需放在 BizDataMw 之后
+func ClientIdentityMW(c *gin.Context) {
+	if identity := clientIdentity(c); identity != "" {
+		c.Set(ClientIdentityKey, identity)
+		if bizData := biz.GetBizData(c); bizData != nil {
+			bizData.SetKey(ClientIdentityKey, identity)
+		}
+	}
+	c.Next()
+}
+
+func clientIdentity(c *gin.Context) string {
+	state := c.Request.TLS
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	leaf := state.VerifiedChains[0][0]
+	if len(leaf.URIs) != 0 {
+		return leaf.URIs[0].String()
+	}
+	return leaf.Subject.CommonName
+}
@@ -0,0 +1,140 @@
+package gin_server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ragpanda/go-toolkit/biz"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, cn string, parent *testCert, template *x509.Certificate) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template.SerialNumber = serial
+	template.Subject = pkix.Name{CommonName: cn}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func TestTLSReloadAndClientIdentity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+
+	ca := newTestCert(t, "test-ca", nil, &x509.Certificate{
+		IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign,
+	})
+	newServerCert := func(cn string) *testCert {
+		return newTestCert(t, cn, ca, &x509.Certificate{
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		})
+	}
+	spiffeID, _ := url.Parse("spiffe://example.org/order-service")
+	client := newTestCert(t, "order-service", ca, &x509.Certificate{
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		URIs:        []*url.URL{spiffeID},
+	})
+
+	config := &TLSConfig{
+		Enable:       true,
+		CertFile:     filepath.Join(dir, "server.pem"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.pem"),
+	}
+	writeServerCert := func(cert *testCert, modTime time.Time) {
+		assert.NoError(t, os.WriteFile(config.CertFile, cert.certPEM, 0o600))
+		assert.NoError(t, os.WriteFile(config.KeyFile, cert.keyPEM, 0o600))
+		assert.NoError(t, os.Chtimes(config.CertFile, modTime, modTime))
+	}
+	assert.NoError(t, os.WriteFile(config.ClientCAFile, ca.certPEM, 0o600))
+	writeServerCert(newServerCert("server-v1"), time.Now().Add(-time.Minute))
+
+	reloader, err := newCertReloader(config)
+	assert.NoError(t, err)
+	tlsConfig, err := reloader.tlsConfig()
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.Use(BizDataMw, ClientIdentityMW)
+	router.GET("/whoami", func(c *gin.Context) {
+		c.String(http.StatusOK, biz.GetBizData(c).GetKey(ClientIdentityKey).(string))
+	})
+	server := httptest.NewUnstartedServer(router)
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientCert, err := tls.X509KeyPair(client.certPEM, client.keyPEM)
+	assert.NoError(t, err)
+	newClient := func(withCert bool) *http.Client {
+		config := &tls.Config{RootCAs: roots}
+		if withCert {
+			config.Certificates = []tls.Certificate{clientCert}
+		}
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	}
+
+	resp, err := newClient(true).Get(server.URL + "/whoami")
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "spiffe://example.org/order-service", string(body))
+	assert.Equal(t, "server-v1", resp.TLS.PeerCertificates[0].Subject.CommonName)
+
+	_, err = newClient(false).Get(server.URL + "/whoami")
+	assert.Error(t, err)
+
+	writeServerCert(newServerCert("server-v2"), time.Now())
+	assert.True(t, reloader.changed())
+	assert.NoError(t, reloader.load())
+	assert.False(t, reloader.changed())
+
+	resp, err = newClient(true).Get(server.URL + "/whoami")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "server-v2", resp.TLS.PeerCertificates[0].Subject.CommonName)
+}
@@ -15,20 +15,27 @@ type Label struct {
 }
 
 func EmitCounter(name string, value float32, labels ...Label) {
-	key := append([]string{name}, labelsToKeys(labels)...)
-	metrics.IncrCounter(key, value)
+	metrics.IncrCounterWithLabels([]string{name}, value, toMetricsLabels(labels))
 }
 
 func EmitTimer(name string, value time.Duration, labels ...Label) {
-	key := append([]string{name}, labelsToKeys(labels)...)
-	metrics.AddSample(key, float32(value.Nanoseconds()))
+	metrics.AddSampleWithLabels([]string{name}, float32(value.Nanoseconds()), toMetricsLabels(labels))
 }
 
+// EmitKey go-metrics has no labeled variant of EmitKey, label values are appended to the key
 func EmitKey(name string, value float32, labels ...Label) {
 	key := append([]string{name}, labelsToKeys(labels)...)
 	metrics.EmitKey(key, value)
 }
 
+func EmitSample(name string, value float32, labels ...Label) {
+	metrics.AddSampleWithLabels([]string{name}, value, toMetricsLabels(labels))
+}
+
+func EmitGauge(name string, value float32, labels ...Label) {
+	metrics.SetGaugeWithLabels([]string{name}, value, toMetricsLabels(labels))
+}
+
 func MapToLabel(m map[string]string) []Label {
 	labels := make([]Label, 0, len(m))
 	for k, v := range m {
@@ -74,6 +81,14 @@ func labelsToKeys(labels []Label) []string {
 	return keys
 }
 
+func toMetricsLabels(labels []Label) []metrics.Label {
+	result := make([]metrics.Label, len(labels))
+	for i, label := range labels {
+		result[i] = metrics.Label{Name: label.Name, Value: label.Value}
+	}
+	return result
+}
+
 func toString(v interface{}) string {
 	switch val := v.(type) {
 	case string:
@@ -5,4 +5,5 @@ type MetricsHubConfig struct {
 	BackendType MetricsBackendType
 
 	ExpirationSec int64
+	EnableInmem   bool
 }
@@ -2,6 +2,9 @@ package metrics
 
 import (
 	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -12,6 +15,7 @@ type APIRequest struct {
 	Success          bool
 	Duration         time.Duration
 	BusinessCategory string
+	ResponseSize     int
 }
 
 func (r APIRequest) ToLabels() []Label {
@@ -28,6 +32,78 @@ func RecordAPIRequest(req APIRequest) {
 	labels := req.ToLabels()
 	EmitCounter("api.requests", 1, labels...)
 	EmitTimer("api.duration", req.Duration, labels...)
+	EmitSample("api.response_size", float32(req.ResponseSize), labels...)
+}
+
+type APIInFlight struct {
+	Method string
+	Path   string
+}
+
+func (r APIInFlight) ToLabels() []Label {
+	return []Label{
+		{Name: "Method", Value: r.Method},
+		{Name: "Path", Value: r.Path},
+	}
+}
+
+var apiInFlight sync.Map
+
+func StartAPIRequest(r APIInFlight) func() {
+	labels := r.ToLabels()
+	counter, _ := apiInFlight.LoadOrStore(strings.Join(labelsToKeys(labels), "\x00"), new(int64))
+	EmitGauge("api.in_flight", float32(atomic.AddInt64(counter.(*int64), 1)), labels...)
+	return func() {
+		EmitGauge("api.in_flight", float32(atomic.AddInt64(counter.(*int64), -1)), labels...)
+	}
+}
+
+type APIPanic struct {
+	Method string
+	Path   string
+}
+
+func (p APIPanic) ToLabels() []Label {
+	return []Label{
+		{Name: "Method", Value: p.Method},
+		{Name: "Path", Value: p.Path},
+	}
+}
+
+func RecordAPIPanic(p APIPanic) {
+	EmitCounter("api.panics", 1, p.ToLabels()...)
+}
+
+type APITimeout struct {
+	Method string
+	Path   string
+}
+
+func (t APITimeout) ToLabels() []Label {
+	return []Label{
+		{Name: "Method", Value: t.Method},
+		{Name: "Path", Value: t.Path},
+	}
+}
+
+func RecordAPITimeout(t APITimeout) {
+	EmitCounter("api.timeouts", 1, t.ToLabels()...)
+}
+
+type APICache struct {
+	Path   string
+	Result string
+}
+
+func (a APICache) ToLabels() []Label {
+	return []Label{
+		{Name: "Path", Value: a.Path},
+		{Name: "Result", Value: a.Result},
+	}
+}
+
+func RecordAPICache(a APICache) {
+	EmitCounter("api.cache", 1, a.ToLabels()...)
 }
 
 type DBOperation struct {
@@ -2,21 +2,24 @@ package metrics
 
 import (
 	"context"
+	"net/http"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	go_metric "github.com/hashicorp/go-metrics"
 	go_metric_prometheus "github.com/hashicorp/go-metrics/prometheus"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/ragpanda/go-toolkit/log"
+	"github.com/ragpanda/go-toolkit/utils"
 )
 
 type MetricsHub struct {
 	args   *MetricsHubConfig
 	config *go_metric.Config
 
-	register *prometheus.Registry
-
-	prometheusRegistry prometheus.Registerer
+	prometheusRegistry *prometheus.Registry
 	prometheusSink     *go_metric_prometheus.PrometheusSink
 	memSink            *go_metric.InmemSink
 }
@@ -78,15 +81,23 @@ func (self *MetricsHub) Release() {
 }
 
 func (self *MetricsHub) setInMemeBackend() error {
-	sink := go_metric.NewInmemSink(10*time.Second, 5*time.Minute)
-	self.memSink = sink
-	go_metric.NewGlobal(self.config, self.memSink)
+	self.memSink = newInmemSink()
+	_, err := go_metric.NewGlobal(self.config, self.memSink)
+	return err
+}
 
-	return nil
+func newInmemSink() *go_metric.InmemSink {
+	return go_metric.NewInmemSink(10*time.Second, 5*time.Minute)
 }
 
 func (self *MetricsHub) setPrometheusBackend() error {
 	reg := prometheus.NewRegistry()
+	if err := reg.Register(collectors.NewGoCollector()); err != nil {
+		return err
+	}
+	if err := reg.Register(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{})); err != nil {
+		return err
+	}
 	sink, err := go_metric_prometheus.NewPrometheusSinkFrom(go_metric_prometheus.PrometheusOpts{
 		Expiration:         time.Duration(self.args.ExpirationSec) * time.Second,
 		Registerer:         reg,
@@ -100,10 +111,43 @@ func (self *MetricsHub) setPrometheusBackend() error {
 	}
 	self.prometheusRegistry = reg
 	self.prometheusSink = sink
-	self.register = prometheus.NewRegistry()
+	// go runtime metrics are exported by the go collector
+	self.config.EnableRuntimeMetrics = false
 
-	go_metric.NewGlobal(self.config, self.memSink)
-	return nil
+	var metricSink go_metric.MetricSink = sink
+	if self.args.EnableInmem {
+		self.memSink = newInmemSink()
+		metricSink = go_metric.FanoutSink{sink, self.memSink}
+	}
+	_, err = go_metric.NewGlobal(self.config, metricSink)
+	return err
+}
+
+func (self *MetricsHub) Handler() http.Handler {
+	if self.prometheusRegistry != nil {
+		return promhttp.HandlerFor(self.prometheusRegistry, promhttp.HandlerOpts{Registry: self.prometheusRegistry})
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := self.memSink.DisplayMetrics(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(utils.MustJsonEncodeBytes(data))
+	})
+}
+
+func (self *MetricsHub) GinHandler() gin.HandlerFunc {
+	return gin.WrapH(self.Handler())
+}
+
+func (self *MetricsHub) GetPrometheusRegistry() *prometheus.Registry {
+	return self.prometheusRegistry
+}
+
+func (self *MetricsHub) GetInmemSink() *go_metric.InmemSink {
+	return self.memSink
 }
 
 func (self *MetricsHub) releaseInMemeBackend() {
@@ -111,5 +155,5 @@ func (self *MetricsHub) releaseInMemeBackend() {
 }
 
 func (self *MetricsHub) releasePrometheusBackend() {
-	self.register.Unregister(self.prometheusSink)
+	self.prometheusRegistry.Unregister(self.prometheusSink)
 }
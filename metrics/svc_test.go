@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusHandler(t *testing.T) {
+	hub, err := NewMetricsHub(context.Background(), &MetricsHubConfig{
+		ServiceName: "svc",
+		BackendType: PrometheusBackendType,
+		EnableInmem: true,
+	})
+	assert.NoError(t, err)
+	defer hub.Release()
+
+	RecordAPIPanic(APIPanic{Method: "GET", Path: "/users/:id"})
+
+	server := httptest.NewServer(hub.Handler())
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	assert.Contains(t, string(body), `svc_api_panics{Method="GET",Path="/users/:id"} 1`)
+	assert.Contains(t, string(body), "go_goroutines")
+	assert.Contains(t, string(body), "process_cpu_seconds_total")
+
+	data := hub.GetInmemSink().Data()
+	assert.NotEmpty(t, data[len(data)-1].Counters)
+}
@@ -8,9 +8,11 @@ import (
 	"time"
 
 	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/health"
 	"github.com/ragpanda/go-toolkit/log"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 type MongoConfig struct {
@@ -169,3 +171,32 @@ func (pool *MongoDBPool) String() string {
 	}
 	return fmt.Sprintf("MongoDBPool: \n%s", strings.Join(sList, ",\n"))
 }
+
+// Ping pings every distinct connection in the pool, aliases sharing a client are pinged once
+func (pool *MongoDBPool) Ping(ctx context.Context) error {
+	pinged := make(map[*mongo.Client]bool)
+	var err error
+	pool.connectionMap.Range(func(key, value interface{}) bool {
+		client := value.(*mongo.Client)
+		if pinged[client] {
+			return true
+		}
+		pinged[client] = true
+		if pingErr := client.Ping(ctx, readpref.Primary()); pingErr != nil {
+			err = fmt.Errorf("ping mongodb %v failed: %w", key, pingErr)
+			return false
+		}
+		return true
+	})
+	return err
+}
+
+// HealthCheck returns a health check pinging the pool, register it with health.Register
+func (pool *MongoDBPool) HealthCheck(critical bool) *health.Check {
+	return &health.Check{
+		Name:     "mongodb",
+		Check:    pool.Ping,
+		Timeout:  2 * time.Second,
+		Critical: critical,
+	}
+}
@@ -0,0 +1,104 @@
+package mongolib
+
+import (
+	"context"
+	"time"
+
+	"github.com/ragpanda/go-toolkit/utils/idempotency"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IdempotencyStore idempotency.Store backed by a mongodb collection, records are keyed by _id and
+// expired by a TTL index on expire_at
+type IdempotencyStore struct {
+	collection *mongo.Collection
+}
+
+var _ idempotency.Store = (*IdempotencyStore)(nil)
+
+func NewIdempotencyStore(client *mongo.Client, dbName string, collectionName string) *IdempotencyStore {
+	return &IdempotencyStore{collection: client.Database(dbName).Collection(collectionName)}
+}
+
+// EnsureIndexes creates the TTL index, mongodb removes expired documents in the background
+// so Acquire still checks expire_at itself
+func (self *IdempotencyStore) EnsureIndexes(ctx context.Context) error {
+	_, err := self.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expire_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+func (self *IdempotencyStore) Acquire(ctx context.Context, key string, requestHash string, lockTTL time.Duration) (string, *idempotency.Record, error) {
+	owner, err := idempotency.NewOwnerToken()
+	if err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	lock := &idempotency.Record{
+		Key:         key,
+		Owner:       owner,
+		RequestHash: requestHash,
+		State:       idempotency.StateInFlight,
+		ExpireAt:    now.Add(lockTTL),
+	}
+	_, err = self.collection.InsertOne(ctx, lock)
+	if err == nil {
+		return owner, nil, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return "", nil, err
+	}
+
+	// take over an expired record which has not been removed by the TTL monitor yet
+	result, err := self.collection.ReplaceOne(ctx, bson.M{"_id": key, "expire_at": bson.M{"$lte": now}}, lock)
+	if err != nil {
+		return "", nil, err
+	}
+	if result.MatchedCount > 0 {
+		return owner, nil, nil
+	}
+
+	existing := &idempotency.Record{}
+	err = self.collection.FindOne(ctx, bson.M{"_id": key}).Decode(existing)
+	if err == mongo.ErrNoDocuments {
+		// removed between insert and find, let the caller retry
+		return "", &idempotency.Record{Key: key, RequestHash: requestHash, State: idempotency.StateInFlight}, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return "", existing, nil
+}
+
+// Complete only updates the in-flight record still held by owner, a lock taken over by another
+// request after expiring is left untouched
+func (self *IdempotencyStore) Complete(ctx context.Context, key string, owner string, record *idempotency.Record, ttl time.Duration) error {
+	result, err := self.collection.UpdateOne(ctx, self.ownedFilter(key, owner), bson.M{"$set": bson.M{
+		"request_hash": record.RequestHash,
+		"state":        idempotency.StateCompleted,
+		"status_code":  record.StatusCode,
+		"header":       record.Header,
+		"body":         record.Body,
+		"expire_at":    time.Now().Add(ttl),
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return idempotency.ErrLockLost
+	}
+	return nil
+}
+
+func (self *IdempotencyStore) Release(ctx context.Context, key string, owner string) error {
+	_, err := self.collection.DeleteOne(ctx, self.ownedFilter(key, owner))
+	return err
+}
+
+func (self *IdempotencyStore) ownedFilter(key string, owner string) bson.M {
+	return bson.M{"_id": key, "owner": owner, "state": idempotency.StateInFlight}
+}
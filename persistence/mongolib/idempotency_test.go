@@ -0,0 +1,102 @@
+package mongolib
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ragpanda/go-toolkit/utils/idempotency"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// firstStatement returns the first document in the given array field of the oldest unread command
+func firstStatement(mt *mtest.T, field string) bson.Raw {
+	event := mt.GetStartedEvent()
+	if !assert.NotNil(mt, event) {
+		return nil
+	}
+	return event.Command.Lookup(field).Array().Index(0).Value().Document()
+}
+
+func TestIdempotencyStore(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("acquire", func(mt *mtest.T) {
+		store := &IdempotencyStore{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		owner, existing, err := store.Acquire(ctx, "u1:k1", "hash", time.Minute)
+		assert.NoError(mt, err)
+		assert.Nil(mt, existing)
+		assert.NotEmpty(mt, owner)
+		doc := firstStatement(mt, "documents")
+		assert.Equal(mt, owner, doc.Lookup("owner").StringValue())
+		assert.Equal(mt, string(idempotency.StateInFlight), doc.Lookup("state").StringValue())
+
+		// key held by another request which has not expired yet
+		ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key"}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: "u1:k1"},
+				{Key: "owner", Value: owner},
+				{Key: "request_hash", Value: "hash"},
+				{Key: "state", Value: string(idempotency.StateCompleted)},
+				{Key: "status_code", Value: 201},
+			}),
+		)
+		other, existing, err := store.Acquire(ctx, "u1:k1", "hash", time.Minute)
+		assert.NoError(mt, err)
+		assert.Empty(mt, other)
+		assert.Equal(mt, idempotency.StateCompleted, existing.State)
+		assert.Equal(mt, 201, existing.StatusCode)
+
+		// expired lock is taken over with a new owner
+		mt.ClearEvents()
+		mt.AddMockResponses(
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key"}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		)
+		other, existing, err = store.Acquire(ctx, "u1:k1", "hash", time.Minute)
+		assert.NoError(mt, err)
+		assert.Nil(mt, existing)
+		assert.NotEmpty(mt, other)
+		assert.NotEqual(mt, owner, other)
+		mt.GetStartedEvent()
+		replace := firstStatement(mt, "updates")
+		assert.Equal(mt, other, replace.Lookup("u", "owner").StringValue())
+	})
+
+	mt.Run("complete", func(mt *mtest.T) {
+		store := &IdempotencyStore{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+		err := store.Complete(ctx, "u1:k1", "owner-1", &idempotency.Record{RequestHash: "hash", StatusCode: 200}, time.Hour)
+		assert.NoError(mt, err)
+		update := firstStatement(mt, "updates")
+		assert.Equal(mt, "owner-1", update.Lookup("q", "owner").StringValue())
+		assert.Equal(mt, string(idempotency.StateInFlight), update.Lookup("q", "state").StringValue())
+		_, err = update.LookupErr("upsert")
+		assert.Error(mt, err)
+
+		// lock taken over by another request
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+		err = store.Complete(ctx, "u1:k1", "owner-1", &idempotency.Record{RequestHash: "hash", StatusCode: 200}, time.Hour)
+		assert.Equal(mt, idempotency.ErrLockLost, err)
+	})
+
+	mt.Run("release", func(mt *mtest.T) {
+		store := &IdempotencyStore{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}))
+		assert.NoError(mt, store.Release(ctx, "u1:k1", "owner-1"))
+		filter := firstStatement(mt, "deletes").Lookup("q").Document()
+		assert.Equal(mt, "u1:k1", filter.Lookup("_id").StringValue())
+		assert.Equal(mt, "owner-1", filter.Lookup("owner").StringValue())
+		assert.Equal(mt, string(idempotency.StateInFlight), filter.Lookup("state").StringValue())
+	})
+}
@@ -0,0 +1,36 @@
+package httpcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreBound(t *testing.T) {
+	ctx := context.Background()
+
+	// the least recently used entry is evicted beyond maxEntries
+	store := NewMemoryStore(2, 0)
+	for _, key := range []string{"a", "b", "c"} {
+		assert.NoError(t, store.Set(ctx, key, &Entry{Body: []byte(key), Tags: []string{"t"}, ExpireAt: farFuture()}))
+	}
+	assert.Equal(t, 2, store.Len())
+	entry, _ := store.Get(ctx, "a")
+	assert.Nil(t, entry)
+	assert.NoError(t, store.InvalidateTags(ctx, "t"))
+	assert.Equal(t, 0, store.Len())
+
+	// body bytes beyond maxBytes evict the oldest entries
+	store = NewMemoryStore(0, 10)
+	assert.NoError(t, store.Set(ctx, "a", &Entry{Body: []byte("12345"), ExpireAt: farFuture()}))
+	assert.NoError(t, store.Set(ctx, "b", &Entry{Body: []byte("12345"), ExpireAt: farFuture()}))
+	assert.Equal(t, 1, store.Len())
+	entry, _ = store.Get(ctx, "b")
+	assert.NotNil(t, entry)
+}
+
+func farFuture() time.Time {
+	return time.Now().Add(time.Hour)
+}
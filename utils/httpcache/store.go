@@ -0,0 +1,42 @@
+package httpcache
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Entry is a cached http response
+type Entry struct {
+	StatusCode int         `json:"StatusCode"`
+	Header     http.Header `json:"Header"`
+	Body       []byte      `json:"Body"`
+	ETag       string      `json:"ETag"`
+	Tags       []string    `json:"Tags"`
+	ExpireAt   time.Time   `json:"ExpireAt"`
+}
+
+// Size approximate memory used by the entry
+func (e *Entry) Size() int64 {
+	size := int64(len(e.Body) + len(e.ETag))
+	for k, v := range e.Header {
+		size += int64(len(k))
+		for _, s := range v {
+			size += int64(len(s))
+		}
+	}
+	for _, tag := range e.Tags {
+		size += int64(len(tag))
+	}
+	return size
+}
+
+// Store keeps cached responses, implementations backed by redis etc. can be shared between instances
+type Store interface {
+	// Get returns nil when key is absent or expired
+	Get(ctx context.Context, key string) (*Entry, error)
+	Set(ctx context.Context, key string, entry *Entry) error
+	Delete(ctx context.Context, key string) error
+	// InvalidateTags removes every entry carrying any of tags
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
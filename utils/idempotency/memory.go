@@ -0,0 +1,99 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore in-process Store, only suitable for single instance deployment
+type MemoryStore struct {
+	lock      sync.Mutex
+	records   map[string]*Record
+	lastPurge time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: make(map[string]*Record),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Acquire(ctx context.Context, key string, requestHash string, lockTTL time.Duration) (string, *Record, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.now()
+	s.purge(now)
+	if record, ok := s.records[key]; ok && now.Before(record.ExpireAt) {
+		return "", record.clone(), nil
+	}
+	owner, err := NewOwnerToken()
+	if err != nil {
+		return "", nil, err
+	}
+	s.records[key] = &Record{
+		Key:         key,
+		Owner:       owner,
+		RequestHash: requestHash,
+		State:       StateInFlight,
+		ExpireAt:    now.Add(lockTTL),
+	}
+	return owner, nil, nil
+}
+
+func (s *MemoryStore) Complete(ctx context.Context, key string, owner string, record *Record, ttl time.Duration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.heldBy(key, owner) {
+		return ErrLockLost
+	}
+	stored := record.clone()
+	stored.Key = key
+	stored.Owner = owner
+	stored.State = StateCompleted
+	stored.ExpireAt = s.now().Add(ttl)
+	s.records[key] = stored
+	return nil
+}
+
+func (s *MemoryStore) Release(ctx context.Context, key string, owner string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.heldBy(key, owner) {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+// heldBy an expired lock still belongs to its owner until another request takes it over
+func (s *MemoryStore) heldBy(key string, owner string) bool {
+	record, ok := s.records[key]
+	return ok && record.State == StateInFlight && record.Owner == owner
+}
+
+// purge drops expired records at most once per minute
+func (s *MemoryStore) purge(now time.Time) {
+	if now.Sub(s.lastPurge) < time.Minute {
+		return
+	}
+	for k, record := range s.records {
+		if !now.Before(record.ExpireAt) {
+			delete(s.records, k)
+		}
+	}
+	s.lastPurge = now
+}
+
+func (r *Record) clone() *Record {
+	c := *r
+	c.Header = r.Header.Clone()
+	if r.Body != nil {
+		c.Body = append([]byte(nil), r.Body...)
+	}
+	return &c
+}
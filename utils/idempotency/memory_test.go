@@ -0,0 +1,54 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreOwner(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	owner, existing, err := s.Acquire(ctx, "u1:k1", "hash", time.Minute)
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+	assert.NotEmpty(t, owner)
+
+	// held by the first request
+	other, existing, err := s.Acquire(ctx, "u1:k1", "hash", time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, other)
+	assert.Equal(t, StateInFlight, existing.State)
+
+	// lock expired and taken over, the stale owner can neither complete nor release it
+	now = now.Add(2 * time.Minute)
+	other, existing, err = s.Acquire(ctx, "u1:k1", "hash", time.Minute)
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+	assert.NotEqual(t, owner, other)
+
+	assert.Equal(t, ErrLockLost, s.Complete(ctx, "u1:k1", owner, &Record{RequestHash: "hash", StatusCode: 200}, time.Hour))
+	assert.NoError(t, s.Release(ctx, "u1:k1", owner))
+	_, existing, _ = s.Acquire(ctx, "u1:k1", "hash", time.Minute)
+	assert.Equal(t, StateInFlight, existing.State)
+
+	// the current owner completes, release afterwards keeps the result
+	assert.NoError(t, s.Complete(ctx, "u1:k1", other, &Record{RequestHash: "hash", StatusCode: 201, Body: []byte("ok")}, time.Hour))
+	assert.NoError(t, s.Release(ctx, "u1:k1", other))
+	_, existing, _ = s.Acquire(ctx, "u1:k1", "hash", time.Minute)
+	assert.Equal(t, StateCompleted, existing.State)
+	assert.Equal(t, 201, existing.StatusCode)
+	assert.Equal(t, []byte("ok"), existing.Body)
+
+	// the owner releases its own lock so the request can be retried
+	owner, _, _ = s.Acquire(ctx, "u1:k2", "hash", time.Minute)
+	assert.NoError(t, s.Release(ctx, "u1:k2", owner))
+	owner, existing, _ = s.Acquire(ctx, "u1:k2", "hash", time.Minute)
+	assert.Nil(t, existing)
+	assert.NotEmpty(t, owner)
+}
@@ -0,0 +1,57 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// State of an idempotency record
+type State string
+
+const (
+	// StateInFlight the first request holding the key is still being processed
+	StateInFlight State = "in_flight"
+	// StateCompleted the response of the first request has been stored and can be replayed
+	StateCompleted State = "completed"
+)
+
+// ErrLockLost the in-flight lock of the key expired and was taken over by another request
+var ErrLockLost = errors.New("idempotency lock lost")
+
+// Record is what a Store keeps for one scoped idempotency key
+type Record struct {
+	Key         string      `json:"Key" bson:"_id"`
+	Owner       string      `json:"Owner" bson:"owner"`
+	RequestHash string      `json:"RequestHash" bson:"request_hash"`
+	State       State       `json:"State" bson:"state"`
+	StatusCode  int         `json:"StatusCode" bson:"status_code"`
+	Header      http.Header `json:"Header" bson:"header"`
+	Body        []byte      `json:"Body" bson:"body"`
+	ExpireAt    time.Time   `json:"ExpireAt" bson:"expire_at"`
+}
+
+// Store persists idempotency records, implementations must make Acquire atomic across instances sharing the store
+type Store interface {
+	// Acquire locks key for lockTTL if it is absent or expired and returns the owner token of the lock,
+	// otherwise returns the existing record without touching it
+	Acquire(ctx context.Context, key string, requestHash string, lockTTL time.Duration) (string, *Record, error)
+	// Complete stores the response of the request holding key and keeps it for ttl,
+	// returns ErrLockLost if the lock is no longer held by owner
+	Complete(ctx context.Context, key string, owner string, record *Record, ttl time.Duration) error
+	// Release drops an in-flight key held by owner so the request can be retried, completed records
+	// and locks taken over by others are kept
+	Release(ctx context.Context, key string, owner string) error
+}
+
+// NewOwnerToken random token identifying the holder of a lock
+func NewOwnerToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
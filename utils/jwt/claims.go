@@ -0,0 +1,62 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Claims JWT payload, numbers are decoded as json.Number
+type Claims map[string]interface{}
+
+func (c Claims) Subject() string {
+	return c.String("sub")
+}
+
+// Audience aud may be a single string or a string array
+func (c Claims) Audience() []string {
+	switch v := c["aud"].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// String return claim as string, non-string values are formatted
+func (c Claims) String(key string) string {
+	switch v := c[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// Time return NumericDate claim such as exp/nbf/iat
+func (c Claims) Time(key string) (time.Time, bool) {
+	switch v := c[key].(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(int64(f), 0), true
+	case float64:
+		return time.Unix(int64(v), 0), true
+	case int64:
+		return time.Unix(v, 0), true
+	case int:
+		return time.Unix(int64(v), 0), true
+	}
+	return time.Time{}, false
+}
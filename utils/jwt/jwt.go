@@ -0,0 +1,218 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/ragpanda/go-toolkit/utils"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+type VerifierConfig struct {
+	// Secret shared secret for HS256, HS256 is rejected if empty
+	Secret string `yaml:"Secret" json:"Secret"`
+	// JWKSFile local JWKS file providing RS256/ES256 public keys
+	JWKSFile string `yaml:"JWKSFile" json:"JWKSFile"`
+	// Audience token aud must contain one of them, not checked if empty
+	Audience []string `yaml:"Audience" json:"Audience"`
+	// Issuer token iss must equal it, not checked if empty
+	Issuer string `yaml:"Issuer" json:"Issuer"`
+	// LeewaySec tolerated clock skew when checking exp/nbf
+	LeewaySec int64 `yaml:"LeewaySec" json:"LeewaySec"`
+	// RequireExp reject tokens without exp, defaults to true when unset
+	RequireExp *bool `yaml:"RequireExp" json:"RequireExp"`
+}
+
+func (c *VerifierConfig) requireExp() bool {
+	return c.RequireExp == nil || *c.RequireExp
+}
+
+// Verifier verify HS256/RS256/ES256 signed JWT and its registered claims
+type Verifier struct {
+	config VerifierConfig
+	keys   map[string]crypto.PublicKey
+	now    func() time.Time
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+func NewVerifier(config *VerifierConfig) (*Verifier, error) {
+	v := &Verifier{
+		config: *config,
+		keys:   map[string]crypto.PublicKey{},
+		now:    time.Now,
+	}
+	if config.JWKSFile != "" {
+		data, err := os.ReadFile(config.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		if v.keys, err = ParseJWKS(data); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// Verify check signature, exp, nbf, iss and aud of the token, return its claims
+func (v *Verifier) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, bizerr.ErrUnauthorized.WithMessage("malformed token")
+	}
+
+	h := &header{}
+	if err := decodeSegment(parts[0], h); err != nil {
+		return nil, bizerr.ErrUnauthorized.WithMessage("malformed token header")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, bizerr.ErrUnauthorized.WithMessage("malformed token signature")
+	}
+	if err := v.verifySignature(h, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	claims := Claims{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, bizerr.ErrUnauthorized.WithMessage("malformed token claims")
+	}
+	if err := v.verifyClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *Verifier) verifySignature(h *header, signingInput string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signingInput))
+	switch h.Alg {
+	case AlgHS256:
+		if v.config.Secret == "" {
+			return bizerr.ErrUnauthorized.WithMessage("HS256 is not allowed")
+		}
+		mac := hmac.New(sha256.New, []byte(v.config.Secret))
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return bizerr.ErrUnauthorized.WithMessage("invalid token signature")
+		}
+		return nil
+	case AlgRS256:
+		key, ok := v.lookupKey(h.Kid, func(k crypto.PublicKey) bool { _, ok := k.(*rsa.PublicKey); return ok })
+		if !ok {
+			return bizerr.ErrUnauthorized.WithMessage("no key for token")
+		}
+		if rsa.VerifyPKCS1v15(key.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) != nil {
+			return bizerr.ErrUnauthorized.WithMessage("invalid token signature")
+		}
+		return nil
+	case AlgES256:
+		key, ok := v.lookupKey(h.Kid, func(k crypto.PublicKey) bool { _, ok := k.(*ecdsa.PublicKey); return ok })
+		if !ok {
+			return bizerr.ErrUnauthorized.WithMessage("no key for token")
+		}
+		if len(signature) != 64 {
+			return bizerr.ErrUnauthorized.WithMessage("invalid token signature")
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(key.(*ecdsa.PublicKey), digest[:], r, s) {
+			return bizerr.ErrUnauthorized.WithMessage("invalid token signature")
+		}
+		return nil
+	default:
+		return bizerr.ErrUnauthorized.WithMessage(fmt.Sprintf("unsupported token alg %q", h.Alg))
+	}
+}
+
+// lookupKey find key by kid, or the only key of the wanted type if token has no kid
+func (v *Verifier) lookupKey(kid string, match func(crypto.PublicKey) bool) (crypto.PublicKey, bool) {
+	if kid != "" {
+		key, ok := v.keys[kid]
+		return key, ok && match(key)
+	}
+	var found crypto.PublicKey
+	for _, key := range v.keys {
+		if !match(key) {
+			continue
+		}
+		if found != nil {
+			return nil, false
+		}
+		found = key
+	}
+	return found, found != nil
+}
+
+func (v *Verifier) verifyClaims(claims Claims) error {
+	now := v.now()
+	leeway := time.Duration(v.config.LeewaySec) * time.Second
+	exp, ok := claims.Time("exp")
+	if !ok && v.config.requireExp() {
+		return bizerr.ErrUnauthorized.WithMessage("token without exp")
+	}
+	if ok && now.After(exp.Add(leeway)) {
+		return bizerr.ErrUnauthorized.WithMessage("token expired")
+	}
+	if nbf, ok := claims.Time("nbf"); ok && now.Add(leeway).Before(nbf) {
+		return bizerr.ErrUnauthorized.WithMessage("token not valid yet")
+	}
+	if v.config.Issuer != "" && claims.String("iss") != v.config.Issuer {
+		return bizerr.ErrUnauthorized.WithMessage("invalid token issuer")
+	}
+	if len(v.config.Audience) != 0 {
+		matched := false
+		for _, aud := range claims.Audience() {
+			if utils.InSlice(v.config.Audience, aud) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return bizerr.ErrUnauthorized.WithMessage("invalid token audience")
+		}
+	}
+	return nil
+}
+
+// SignHS256 sign claims with HS256, mostly for service-to-service tokens and tests
+func SignHS256(claims Claims, secret string) (string, error) {
+	headerData, err := json.Marshal(&header{Alg: AlgHS256})
+	if err != nil {
+		return "", err
+	}
+	claimsData, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(headerData) + "." + base64.RawURLEncoding.EncodeToString(claimsData)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return utils.Unmarshal(data, v)
+}
@@ -0,0 +1,123 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func sign(t *testing.T, alg, kid string, claims Claims, key crypto.Signer) string {
+	headerData, _ := json.Marshal(&header{Alg: alg, Kid: kid})
+	claimsData, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(headerData) + "." + base64.RawURLEncoding.EncodeToString(claimsData)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		s, err := rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		assert.NoError(t, err)
+		signature = s
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		assert.NoError(t, err)
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerifyHS256(t *testing.T) {
+	v, err := NewVerifier(&VerifierConfig{
+		Secret:   "secret",
+		Audience: []string{"api"},
+		Issuer:   "auth",
+	})
+	assert.NoError(t, err)
+
+	exp := time.Now().Add(time.Hour).Unix()
+	token, err := SignHS256(Claims{"sub": "u1", "aud": []string{"web", "api"}, "iss": "auth", "exp": exp}, "secret")
+	assert.NoError(t, err)
+	claims, err := v.Verify(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "u1", claims.Subject())
+
+	cases := map[string]Claims{
+		"expired":      {"sub": "u1", "aud": "api", "iss": "auth", "exp": time.Now().Add(-time.Hour).Unix()},
+		"not before":   {"sub": "u1", "aud": "api", "iss": "auth", "nbf": time.Now().Add(time.Hour).Unix()},
+		"bad audience": {"sub": "u1", "aud": "other", "iss": "auth", "exp": exp},
+		"bad issuer":   {"sub": "u1", "aud": "api", "iss": "evil", "exp": exp},
+		"missing exp":  {"sub": "u1", "aud": "api", "iss": "auth"},
+	}
+	for name, c := range cases {
+		token, _ := SignHS256(c, "secret")
+		_, err := v.Verify(token)
+		assert.True(t, bizerr.Is(err, bizerr.ErrUnauthorized), name)
+	}
+
+	token, _ = SignHS256(Claims{"sub": "u1", "aud": "api", "iss": "auth"}, "wrong")
+	_, err = v.Verify(token)
+	assert.True(t, bizerr.Is(err, bizerr.ErrUnauthorized))
+
+	v.config.LeewaySec = 120
+	token, _ = SignHS256(Claims{"sub": "u1", "aud": "api", "iss": "auth", "exp": time.Now().Add(-time.Minute).Unix()}, "secret")
+	_, err = v.Verify(token)
+	assert.NoError(t, err)
+
+	// RequireExp 显式关闭时允许不带 exp 的 token
+	requireExp := false
+	v.config.RequireExp = &requireExp
+	token, _ = SignHS256(Claims{"sub": "u1", "aud": "api", "iss": "auth"}, "secret")
+	_, err = v.Verify(token)
+	assert.NoError(t, err)
+}
+
+func TestVerifyJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	enc := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	jwks := map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "n": enc(rsaKey.N.Bytes()), "e": "AQAB"},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": enc(ecKey.X.FillBytes(make([]byte, 32))), "y": enc(ecKey.Y.FillBytes(make([]byte, 32)))},
+	}}
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	data, _ := json.Marshal(jwks)
+	assert.NoError(t, os.WriteFile(file, data, 0o600))
+
+	v, err := NewVerifier(&VerifierConfig{JWKSFile: file})
+	assert.NoError(t, err)
+
+	exp := time.Now().Add(time.Hour).Unix()
+	claims, err := v.Verify(sign(t, AlgRS256, "rsa-1", Claims{"sub": "rsa-user", "exp": exp}, rsaKey))
+	assert.NoError(t, err)
+	assert.Equal(t, "rsa-user", claims.Subject())
+
+	claims, err = v.Verify(sign(t, AlgES256, "", Claims{"sub": "ec-user", "exp": exp}, ecKey))
+	assert.NoError(t, err)
+	assert.Equal(t, "ec-user", claims.Subject())
+
+	// kid 指向类型不符的 key
+	_, err = v.Verify(sign(t, AlgRS256, "ec-1", Claims{"sub": "rsa-user", "exp": exp}, rsaKey))
+	assert.True(t, bizerr.Is(err, bizerr.ErrUnauthorized))
+
+	// 未配置 secret 时拒绝 HS256
+	token, _ := SignHS256(Claims{"sub": "u1"}, "")
+	_, err = v.Verify(token)
+	assert.True(t, bizerr.Is(err, bizerr.ErrUnauthorized))
+}
@@ -43,3 +43,12 @@ func GetNonEmptyStr(s ...string) string {
 func IsBlankStr(s string) bool {
 	return strings.TrimSpace(s) == ""
 }
+
+func GetNonEmptySlice[T any](s ...[]T) []T {
+	for _, p := range s {
+		if len(p) != 0 {
+			return p
+		}
+	}
+	return nil
+}
@@ -14,6 +14,9 @@ type RetryParams struct {
 	RetryFailedInterval time.Duration
 	MaxTimout           time.Duration
 	MaxTimes            int
+	// MaxRetryAfter upper bound of a throttled error's RetryAfterHint,
+	// a longer hint stops retrying instead of blocking the caller
+	MaxRetryAfter time.Duration
 }
 
 func RetryMaxTimout(retryMaxTimout, interval time.Duration) func(*RetryParams) {
@@ -30,11 +33,21 @@ func RetryMaxTimes(maxTimes int, interval time.Duration) func(*RetryParams) {
 	}
 }
 
+// RetryMaxRetryAfter set the upper bound of a throttled error's retry-after hint, default 1 minute
+func RetryMaxRetryAfter(maxRetryAfter time.Duration) func(*RetryParams) {
+	return func(params *RetryParams) {
+		params.MaxRetryAfter = maxRetryAfter
+	}
+}
+
+// Retry exec execFunc until it succeeds or the retry budget is used up,
+// permanent errors (see ClassifyError) and a done ctx stop retrying immediately
 func Retry(ctx context.Context, execFunc func(ctx context.Context) error, options ...RetryOption) error {
 	p := &RetryParams{
 		MaxTimout:           0,
 		RetryFailedInterval: 1 * time.Second,
 		MaxTimes:            3,
+		MaxRetryAfter:       time.Minute,
 	}
 
 	for _, op := range options {
@@ -45,36 +58,43 @@ func Retry(ctx context.Context, execFunc func(ctx context.Context) error, option
 		p.MaxTimes = 1
 	}
 
-	var err error
 	start := time.Now()
+	for execTimes := 1; ; execTimes++ {
+		err := panicSafe(ctx, func(ctx context.Context) error { return execFunc(ctx) })
+		if err == nil {
+			return nil
+		}
+		class := ClassifyError(err)
+		log.Info(ctx, "exec fail, times=%d, class=%s, err=`%s`", execTimes, class, err.Error())
+		if class == RetryClassPermanent || ctx.Err() != nil {
+			return err
+		}
+		if p.MaxTimout == 0 && execTimes >= p.MaxTimes {
+			return err
+		}
 
-	var allowContinue func() bool
-	if p.MaxTimout != 0 {
-		allowContinue = func() bool {
-			return time.Now().Sub(start) < p.MaxTimout
+		interval := p.RetryFailedInterval
+		if hint := RetryAfterHint(err); class == RetryClassThrottled && hint > interval {
+			if hint > p.MaxRetryAfter {
+				log.Info(ctx, "retry-after hint %v exceeds %v, stop retrying", hint, p.MaxRetryAfter)
+				return err
+			}
+			interval = hint
 		}
-	} else {
-		t := 0
-		allowContinue = func() bool {
-			t++
-			return t <= p.MaxTimes
+		// no point in sleeping when the next attempt would start after the budget or the ctx deadline
+		if p.MaxTimout != 0 && time.Since(start)+interval >= p.MaxTimout {
+			return err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= interval {
+			return err
 		}
-	}
 
-	//仅统计
-	execTimes := 0
-	for allowContinue() {
-		execTimes++
-		err = panicSafe(ctx, func(ctx context.Context) error { return execFunc(ctx) })
-		if err != nil {
-			log.Info(ctx, "exec fail, times=%d, err=`%s`", execTimes, err.Error())
-			time.Sleep(p.RetryFailedInterval)
-			continue
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(interval):
 		}
-		return nil
 	}
-
-	return err
 }
 
 func panicSafe(ctx context.Context, execFunc func(ctx context.Context) error) (err error) {
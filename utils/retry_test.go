@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type classifiedError struct {
+	class RetryClass
+}
+
+func (e *classifiedError) Error() string {
+	return e.class.String()
+}
+
+func (e *classifiedError) RetryClass() RetryClass {
+	return e.class
+}
+
+func TestRetryClassify(t *testing.T) {
+	ctx := context.Background()
+
+	times := 0
+	err := Retry(ctx, func(ctx context.Context) error {
+		times++
+		return &classifiedError{class: RetryClassPermanent}
+	}, RetryMaxTimes(3, time.Millisecond))
+	assert.Error(t, err)
+	assert.Equal(t, 1, times)
+
+	times = 0
+	err = Retry(ctx, func(ctx context.Context) error {
+		times++
+		return errors.New("boom")
+	}, RetryMaxTimes(3, time.Millisecond))
+	assert.Error(t, err)
+	assert.Equal(t, 3, times)
+
+	assert.Equal(t, RetryClassPermanent, ClassifyError(context.Canceled))
+	assert.Equal(t, RetryClassRetryable, ClassifyError(context.DeadlineExceeded))
+	assert.Equal(t, RetryClassThrottled, ClassifyHTTPStatus(429))
+	assert.Equal(t, RetryClassPermanent, ClassifyHTTPStatus(404))
+	assert.Equal(t, RetryClassRetryable, ClassifyHTTPStatus(503))
+}
+
+type throttledError struct {
+	retryAfter time.Duration
+}
+
+func (e *throttledError) Error() string {
+	return "throttled"
+}
+
+func (e *throttledError) RetryClass() RetryClass {
+	return RetryClassThrottled
+}
+
+func (e *throttledError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+func TestRetryBudget(t *testing.T) {
+	ctx := context.Background()
+
+	// no sleep after the last attempt
+	start := time.Now()
+	times := 0
+	err := Retry(ctx, func(ctx context.Context) error {
+		times++
+		return errors.New("boom")
+	}, RetryMaxTimes(2, 100*time.Millisecond))
+	assert.Error(t, err)
+	assert.Equal(t, 2, times)
+	assert.Less(t, time.Since(start), 180*time.Millisecond)
+
+	// hint beyond MaxRetryAfter stops retrying at once
+	start = time.Now()
+	times = 0
+	err = Retry(ctx, func(ctx context.Context) error {
+		times++
+		return &throttledError{retryAfter: time.Hour}
+	}, RetryMaxTimes(3, time.Millisecond))
+	assert.Error(t, err)
+	assert.Equal(t, 1, times)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+
+	// hint beyond the ctx deadline stops retrying at once
+	deadlineCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	times = 0
+	err = Retry(deadlineCtx, func(ctx context.Context) error {
+		times++
+		return &throttledError{retryAfter: time.Second}
+	}, RetryMaxTimes(3, time.Millisecond))
+	assert.Error(t, err)
+	assert.Equal(t, 1, times)
+
+	// hint within the budget is honored
+	start = time.Now()
+	times = 0
+	err = Retry(ctx, func(ctx context.Context) error {
+		times++
+		if times == 1 {
+			return &throttledError{retryAfter: 50 * time.Millisecond}
+		}
+		return nil
+	}, RetryMaxTimes(3, time.Millisecond))
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestRetryAttemptTimeout(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+	client := &http.Client{Timeout: 20 * time.Millisecond}
+
+	// a per-attempt client timeout is retried
+	times := 0
+	err := Retry(ctx, func(ctx context.Context) error {
+		times++
+		req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}, RetryMaxTimes(3, time.Millisecond))
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, 3, times)
+
+	// the caller's own deadline stops retrying
+	deadlineCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	times = 0
+	err = Retry(deadlineCtx, func(ctx context.Context) error {
+		times++
+		<-ctx.Done()
+		return ctx.Err()
+	}, RetryMaxTimes(3, time.Millisecond))
+	assert.Error(t, err)
+	assert.Equal(t, 1, times)
+}
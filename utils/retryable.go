@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RetryClass describes whether a failed operation is worth retrying
+type RetryClass int
+
+const (
+	// RetryClassRetryable the error may go away on the next attempt
+	RetryClassRetryable RetryClass = iota
+	// RetryClassPermanent the error will never go away by retrying, e.g. invalid input or 4xx
+	RetryClassPermanent
+	// RetryClassThrottled the callee asks us to slow down, retry after a (possibly hinted) delay
+	RetryClassThrottled
+)
+
+func (c RetryClass) String() string {
+	switch c {
+	case RetryClassRetryable:
+		return "retryable"
+	case RetryClassPermanent:
+		return "permanent"
+	case RetryClassThrottled:
+		return "throttled"
+	default:
+		return "unknown"
+	}
+}
+
+// RetryClassifier is implemented by errors that know their own retry class
+type RetryClassifier interface {
+	RetryClass() RetryClass
+}
+
+// RetryAfterHinter is implemented by errors that carry a suggested retry delay
+type RetryAfterHinter interface {
+	RetryAfter() time.Duration
+}
+
+// ClassifyError classify err by, in order: RetryClassifier, context canceled, net errors,
+// the Temporary() interface; anything else is retryable.
+// Timeouts (including context.DeadlineExceeded, which is a net.Error) are retryable since
+// they may come from a per-attempt timeout such as http.Client.Timeout, Retry itself stops
+// once the caller's ctx is done
+func ClassifyError(err error) RetryClass {
+	if err == nil {
+		return RetryClassRetryable
+	}
+
+	var classifier RetryClassifier
+	if errors.As(err, &classifier) {
+		return classifier.RetryClass()
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return RetryClassPermanent
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return RetryClassRetryable
+	}
+
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) && !temporary.Temporary() {
+		return RetryClassPermanent
+	}
+
+	return RetryClassRetryable
+}
+
+// ClassifyHTTPStatus classify a http response status code,
+// 429 is throttled, 408 and 5xx are retryable, other 4xx are permanent
+func ClassifyHTTPStatus(statusCode int) RetryClass {
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return RetryClassThrottled
+	case statusCode == http.StatusRequestTimeout:
+		return RetryClassRetryable
+	case statusCode >= 400 && statusCode < 500:
+		return RetryClassPermanent
+	default:
+		return RetryClassRetryable
+	}
+}
+
+// RetryAfterHint return the suggested retry delay carried by err, 0 if none
+func RetryAfterHint(err error) time.Duration {
+	var hinter RetryAfterHinter
+	if errors.As(err, &hinter) {
+		return hinter.RetryAfter()
+	}
+	return 0
+}
@@ -0,0 +1,49 @@
+package signature
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NonceStore remembers seen nonces, implementations backed by redis/mongo can be shared between instances
+type NonceStore interface {
+	// CheckAndStore return false if nonce has been seen within ttl, otherwise remember it for ttl
+	CheckAndStore(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+// MemoryNonceStore in-process NonceStore, only suitable for single instance deployment
+type MemoryNonceStore struct {
+	lock      sync.Mutex
+	nonces    map[string]time.Time
+	lastPurge time.Time
+	now       func() time.Time
+}
+
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		nonces: make(map[string]time.Time),
+		now:    time.Now,
+	}
+}
+
+func (s *MemoryNonceStore) CheckAndStore(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastPurge) > ttl {
+		for k, expireAt := range s.nonces {
+			if !now.Before(expireAt) {
+				delete(s.nonces, k)
+			}
+		}
+		s.lastPurge = now
+	}
+
+	if expireAt, ok := s.nonces[nonce]; ok && now.Before(expireAt) {
+		return false, nil
+	}
+	s.nonces[nonce] = now.Add(ttl)
+	return true, nil
+}
@@ -0,0 +1,136 @@
+package signature
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ragpanda/go-toolkit/bizerr"
+)
+
+const (
+	HeaderKeyID     = "X-Signature-Key-Id"
+	HeaderTimestamp = "X-Signature-Timestamp"
+	HeaderNonce     = "X-Signature-Nonce"
+	HeaderSignature = "X-Signature"
+)
+
+// Signer signs requests with HMAC-SHA256, KeyID tells the verifier which secret to use
+type Signer struct {
+	KeyID  string
+	Secret string
+}
+
+// Sign set signature headers on req, body must be the exact bytes sent,
+// call it again for every attempt so each one gets a fresh timestamp and nonce
+func (s *Signer) Sign(req *http.Request, body []byte) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonceStr := hex.EncodeToString(nonce)
+
+	req.Header.Set(HeaderKeyID, s.KeyID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderNonce, nonceStr)
+	req.Header.Set(HeaderSignature, Compute(s.Secret, StringToSign(req, timestamp, nonceStr, body)))
+	return nil
+}
+
+// StringToSign canonical form: method, escaped path, raw query, timestamp, nonce and hex sha256 of body, joined by '\n'
+func StringToSign(req *http.Request, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		timestamp,
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+// Compute base64 encoded HMAC-SHA256 of stringToSign
+func Compute(secret, stringToSign string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+type VerifierConfig struct {
+	// Keys key id -> secret, keep both old and new key during rotation
+	Keys map[string]string `yaml:"Keys" json:"Keys"`
+	// MaxSkewSec max allowed difference between request timestamp and local clock, default 300
+	MaxSkewSec int64 `yaml:"MaxSkewSec" json:"MaxSkewSec"`
+}
+
+// Verifier verify requests signed by Signer and reject replayed nonces
+type Verifier struct {
+	config     VerifierConfig
+	nonceStore NonceStore
+	now        func() time.Time
+}
+
+// NewVerifier create verifier, nonceStore is a MemoryNonceStore if nil
+func NewVerifier(config *VerifierConfig, nonceStore NonceStore) *Verifier {
+	v := &Verifier{
+		config:     *config,
+		nonceStore: nonceStore,
+		now:        time.Now,
+	}
+	if v.config.MaxSkewSec <= 0 {
+		v.config.MaxSkewSec = 300
+	}
+	if v.nonceStore == nil {
+		v.nonceStore = NewMemoryNonceStore()
+	}
+	return v
+}
+
+// Verify check signature headers of req against body, return the key id on success
+func (v *Verifier) Verify(ctx context.Context, req *http.Request, body []byte) (string, error) {
+	keyID := req.Header.Get(HeaderKeyID)
+	timestamp := req.Header.Get(HeaderTimestamp)
+	nonce := req.Header.Get(HeaderNonce)
+	signature := req.Header.Get(HeaderSignature)
+	if keyID == "" || timestamp == "" || nonce == "" || signature == "" {
+		return "", bizerr.ErrUnauthorized.WithMessage("missing signature headers")
+	}
+
+	secret, ok := v.config.Keys[keyID]
+	if !ok {
+		return "", bizerr.ErrUnauthorized.WithMessage(fmt.Sprintf("unknown signature key %q", keyID))
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", bizerr.ErrUnauthorized.WithMessage("invalid signature timestamp")
+	}
+	skew := v.now().Unix() - ts
+	if skew > v.config.MaxSkewSec || skew < -v.config.MaxSkewSec {
+		return "", bizerr.ErrUnauthorized.WithMessage("signature timestamp out of window")
+	}
+
+	expected := Compute(secret, StringToSign(req, timestamp, nonce, body))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return "", bizerr.ErrUnauthorized.WithMessage("invalid signature")
+	}
+
+	// a request may be replayed on either side of the window, so keep nonces for twice the window
+	fresh, err := v.nonceStore.CheckAndStore(ctx, keyID+":"+nonce, 2*time.Duration(v.config.MaxSkewSec)*time.Second)
+	if err != nil {
+		return "", bizerr.Wrap(err, bizerr.InternalError, "check signature nonce failed")
+	}
+	if !fresh {
+		return "", bizerr.ErrUnauthorized.WithMessage("replayed signature nonce")
+	}
+	return keyID, nil
+}
@@ -0,0 +1,137 @@
+package signature
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ragpanda/go-toolkit/bizerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringToSign(t *testing.T) {
+	body := []byte(`{"name":"tom"}`)
+	bodyHash := sha256.Sum256(body)
+	req := httptest.NewRequest("POST", "/api/users%2Fadmin/items?b=2&a=1", nil)
+
+	assert.Equal(t,
+		"POST\n/api/users%2Fadmin/items\nb=2&a=1\n1700000000\nnonce-1\n"+hex.EncodeToString(bodyHash[:]),
+		StringToSign(req, "1700000000", "nonce-1", body))
+
+	// empty body still hashes, so a dropped body cannot pass as an empty one
+	emptyHash := sha256.Sum256(nil)
+	req = httptest.NewRequest("GET", "/ping", nil)
+	assert.Equal(t, "GET\n/ping\n\n1\nn\n"+hex.EncodeToString(emptyHash[:]), StringToSign(req, "1", "n", nil))
+
+	assert.NotEqual(t, Compute("secret", "a"), Compute("secret", "b"))
+	assert.NotEqual(t, Compute("secret", "a"), Compute("other", "a"))
+}
+
+func newSignedRequest(t *testing.T, signer *Signer, body []byte) *http.Request {
+	req := httptest.NewRequest("POST", "/api/orders?id=1", nil)
+	assert.NoError(t, signer.Sign(req, body))
+	return req
+}
+
+func TestVerifySkewWindow(t *testing.T) {
+	ctx := context.Background()
+	body := []byte(`{"amount":1}`)
+	v := NewVerifier(&VerifierConfig{Keys: map[string]string{"k1": "secret"}, MaxSkewSec: 60}, nil)
+
+	req := newSignedRequest(t, &Signer{KeyID: "k1", Secret: "secret"}, body)
+	keyID, err := v.Verify(ctx, req, body)
+	assert.NoError(t, err)
+	assert.Equal(t, "k1", keyID)
+
+	// the same nonce is rejected on replay
+	_, err = v.Verify(ctx, req, body)
+	assert.True(t, bizerr.Is(err, bizerr.ErrUnauthorized))
+
+	// tampered body
+	req = newSignedRequest(t, &Signer{KeyID: "k1", Secret: "secret"}, body)
+	_, err = v.Verify(ctx, req, []byte(`{"amount":100}`))
+	assert.True(t, bizerr.Is(err, bizerr.ErrUnauthorized))
+
+	// timestamps on both edges of the window pass, beyond it they are rejected
+	now := time.Unix(1700000000, 0)
+	v.now = func() time.Time { return now }
+	for offset, ok := range map[int64]bool{-60: true, 60: true, -61: false, 61: false} {
+		timestamp := strconv.FormatInt(now.Unix()+offset, 10)
+		nonce := "nonce" + strconv.FormatInt(offset, 10)
+		req = httptest.NewRequest("POST", "/api/orders", nil)
+		req.Header.Set(HeaderKeyID, "k1")
+		req.Header.Set(HeaderTimestamp, timestamp)
+		req.Header.Set(HeaderNonce, nonce)
+		req.Header.Set(HeaderSignature, Compute("secret", StringToSign(req, timestamp, nonce, body)))
+		_, err = v.Verify(ctx, req, body)
+		if ok {
+			assert.NoError(t, err, offset)
+		} else {
+			assert.True(t, bizerr.Is(err, bizerr.ErrUnauthorized), offset)
+		}
+	}
+
+	// missing headers
+	_, err = v.Verify(ctx, httptest.NewRequest("POST", "/api/orders", nil), body)
+	assert.True(t, bizerr.Is(err, bizerr.ErrUnauthorized))
+}
+
+func TestVerifyKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	body := []byte("payload")
+	oldSigner := &Signer{KeyID: "2023", Secret: "old-secret"}
+	newSigner := &Signer{KeyID: "2024", Secret: "new-secret"}
+
+	// during rotation both keys are accepted
+	v := NewVerifier(&VerifierConfig{Keys: map[string]string{"2023": "old-secret", "2024": "new-secret"}}, nil)
+	for _, signer := range []*Signer{oldSigner, newSigner} {
+		keyID, err := v.Verify(ctx, newSignedRequest(t, signer, body), body)
+		assert.NoError(t, err)
+		assert.Equal(t, signer.KeyID, keyID)
+	}
+
+	// a key id signed with another key's secret is rejected
+	_, err := v.Verify(ctx, newSignedRequest(t, &Signer{KeyID: "2024", Secret: "old-secret"}, body), body)
+	assert.True(t, bizerr.Is(err, bizerr.ErrUnauthorized))
+
+	// once the old key is dropped its signatures are rejected
+	v = NewVerifier(&VerifierConfig{Keys: map[string]string{"2024": "new-secret"}}, nil)
+	_, err = v.Verify(ctx, newSignedRequest(t, oldSigner, body), body)
+	assert.True(t, bizerr.Is(err, bizerr.ErrUnauthorized))
+	_, err = v.Verify(ctx, newSignedRequest(t, newSigner, body), body)
+	assert.NoError(t, err)
+}
+
+func TestMemoryNonceStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	s := NewMemoryNonceStore()
+	s.now = func() time.Time { return now }
+
+	fresh, err := s.CheckAndStore(ctx, "k1:a", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, fresh)
+	fresh, _ = s.CheckAndStore(ctx, "k1:a", time.Minute)
+	assert.False(t, fresh)
+	fresh, _ = s.CheckAndStore(ctx, "k1:b", time.Minute)
+	assert.True(t, fresh)
+
+	// still remembered right before expiry
+	now = now.Add(time.Minute - time.Second)
+	fresh, _ = s.CheckAndStore(ctx, "k1:a", time.Minute)
+	assert.False(t, fresh)
+
+	// accepted again once expired, and expired nonces are purged
+	now = now.Add(2 * time.Minute)
+	fresh, _ = s.CheckAndStore(ctx, "k1:a", time.Minute)
+	assert.True(t, fresh)
+	s.lock.Lock()
+	_, ok := s.nonces["k1:b"]
+	s.lock.Unlock()
+	assert.False(t, ok)
+}